@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/liggitt/tabwriter"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+// colorThemeEnv names the environment variable overriding the color theme.
+const colorThemeEnv = "KGET_COLORS"
+
+// The classes of cells colored by meaning.
+const (
+	colorFailed     = "failed"
+	colorInProgress = "progress"
+	colorHealthy    = "healthy"
+	colorIncomplete = "incomplete"
+
+	// the rows --refresh marks since the previous frame
+	colorAdded   = "added"
+	colorChanged = "changed"
+	colorDeleted = "deleted"
+)
+
+// addColorFlag binds --color to o.Color.
+func addColorFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().StringVar(&o.Color, "color", o.Color, "Color table cells by meaning: failures red, transitions yellow, healthy states green and incomplete ready counts highlighted. One of: auto|always|never. auto colors only when the output is a terminal and NO_COLOR is not set. The colors are set with "+colorThemeEnv+", e.g. "+colorThemeEnv+"='failed=1;31:progress=33:healthy=32:incomplete=35:Evicted=failed'.")
+}
+
+// useColor reports whether the table output is colored, validating --color.
+func (o *GetOptions) useColor() (bool, error) {
+	switch o.Color {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+		return len(os.Getenv("NO_COLOR")) == 0 && term.IsTerminal(o.Out), nil
+	}
+	return false, fmt.Errorf("--color must be one of auto, always or never, got %q", o.Color)
+}
+
+// colorTheme maps cell values to classes and classes to SGR parameters.
+type colorTheme struct {
+	codes  map[string]string
+	states map[string]string
+}
+
+// defaultColorTheme returns the built-in theme, covering the states printed
+// for built-in kinds and the ones computeStatus derives.
+func defaultColorTheme() *colorTheme {
+	t := &colorTheme{
+		codes: map[string]string{
+			colorFailed:     "31",
+			colorInProgress: "33",
+			colorHealthy:    "32",
+			colorIncomplete: "33",
+			colorAdded:      "1",
+			colorChanged:    "7",
+			colorDeleted:    "2;9",
+		},
+		states: map[string]string{},
+	}
+	for class, states := range map[string][]string{
+		colorFailed: {
+			"CrashLoopBackOff", "Error", "NotReady", "Failed", "ImagePullBackOff", "ErrImagePull",
+			"InvalidImageName", "CreateContainerConfigError", "CreateContainerError", "RunContainerError",
+			"OOMKilled", "Evicted", "ContainerStatusUnknown", "Lost", "Unhealthy", "False",
+		},
+		colorInProgress: {
+			"Pending", "ContainerCreating", "PodInitializing", "Terminating", "InProgress", "Progressing",
+			"Waiting", "SchedulingDisabled", "Unknown",
+		},
+		colorHealthy: {
+			"Running", "Ready", "Succeeded", "Completed", "Active", "Bound", "Available", "Current",
+			"Healthy", "True",
+		},
+	} {
+		for _, state := range states {
+			t.states[state] = class
+		}
+	}
+	return t
+}
+
+// parseColorTheme overrides the default theme with a spec of colon separated
+// entries. An entry naming a class sets its SGR parameters (failed=1;31), any
+// other entry puts a state into a class (Evicted=failed).
+func parseColorTheme(spec string) (*colorTheme, error) {
+	t := defaultColorTheme()
+	for _, entry := range strings.Split(spec, ":") {
+		if len(entry) == 0 {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("%s: invalid entry %q, expected class=SGR or state=class", colorThemeEnv, entry)
+		}
+		key, value := parts[0], parts[1]
+		if _, ok := t.codes[key]; ok {
+			for _, param := range strings.Split(value, ";") {
+				if _, err := strconv.Atoi(param); err != nil {
+					return nil, fmt.Errorf("%s: invalid color %q for %s", colorThemeEnv, value, key)
+				}
+			}
+			t.codes[key] = value
+			continue
+		}
+		if _, ok := t.codes[value]; !ok && value != "none" {
+			return nil, fmt.Errorf("%s: unknown class %q for %s, expected one of failed, progress, healthy, incomplete, added, changed, deleted or none", colorThemeEnv, value, key)
+		}
+		t.states[key] = value
+	}
+	return t, nil
+}
+
+// plainColorTheme returns a theme coloring no cell, which only highlights the
+// rows --refresh marks with text attributes.
+func plainColorTheme() *colorTheme {
+	defaults := defaultColorTheme()
+	t := &colorTheme{codes: map[string]string{}, states: map[string]string{}}
+	for _, class := range []string{colorAdded, colorChanged, colorDeleted} {
+		t.codes[class] = defaults.codes[class]
+	}
+	return t
+}
+
+var readyRatio = regexp.MustCompile(`^(\d+)/(\d+)$`)
+
+// classOf returns the class of a cell, or "" if it is not colored.
+func (t *colorTheme) classOf(cell string) string {
+	if m := readyRatio.FindStringSubmatch(cell); m != nil {
+		if m[1] != m[2] {
+			return colorIncomplete
+		}
+		return ""
+	}
+	// node conditions like NotReady,SchedulingDisabled take the worst class
+	class := ""
+	for _, state := range strings.Split(cell, ",") {
+		c, ok := t.states[state]
+		if !ok && strings.HasPrefix(state, "Init:") {
+			// init containers failing or still running, like Init:0/1
+			if c = t.states[strings.TrimPrefix(state, "Init:")]; c != colorFailed {
+				c = colorInProgress
+			}
+		}
+		switch c {
+		case colorFailed:
+			return c
+		case colorInProgress:
+			class = c
+		case colorHealthy:
+			if len(class) == 0 {
+				class = c
+			}
+		}
+	}
+	return class
+}
+
+// tabwriterFlags are the flags of printers.GetNewTabWriter. FilterHTML
+// makes the tabwriter ignore the escape codes colorTable wraps in tags when
+// it computes the column widths.
+const tabwriterFlags = tabwriter.RememberWidths | tabwriter.FilterHTML
+
+// newTableWriter returns the tabwriter aligning table output written to w.
+// When color is used the tabwriter skips the tags holding escape codes, which
+// are unwrapped once the columns were aligned.
+func (o *GetOptions) newTableWriter(w io.Writer) *tabwriter.Writer {
+	if o.colorTheme == nil {
+		return printers.GetNewTabWriter(w)
+	}
+	return tabwriter.NewWriter(&colorWriter{Delegate: w}, 6, 4, 3, ' ', tabwriterFlags)
+}
+
+// cellEscaper escapes the cells of colored tables, which the tabwriter reads
+// as html.
+var cellEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// colorTable escapes the cells of table and wraps them in the escape codes of
+// their class. It is a no-op when color is not used.
+func (t *colorTheme) colorTable(table *metav1.Table) {
+	if t == nil {
+		return
+	}
+	for i := range table.Rows {
+		for j, cell := range table.Rows[i].Cells {
+			// the printer leaves nil cells blank
+			if cell == nil {
+				continue
+			}
+			// the printer writes every cell with fmt.Fprint
+			s := fmt.Sprint(cell)
+			escaped := cellEscaper.Replace(s)
+			if code := t.codes[t.classOf(s)]; len(code) > 0 {
+				escaped = fmt.Sprintf("<\x1b[%sm>%s%s", code, escaped, colorReset)
+			}
+			table.Rows[i].Cells[j] = escaped
+		}
+	}
+}
+
+// labelColumnsPrinter adds the -L and --show-labels columns to the Tables
+// printed by delegate, escaped like the cells of colorTable. The printer would
+// add them unescaped, so that the tabwriter read the <none> of objects without
+// labels as a tag.
+type labelColumnsPrinter struct {
+	delegate     printers.ResourcePrinter
+	columnLabels []string
+	showLabels   bool
+}
+
+func (p *labelColumnsPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	event, isEvent := obj.(*metav1.WatchEvent)
+	if isEvent {
+		obj = event.Object.Object
+	}
+	original, ok := obj.(*metav1.Table)
+	if !ok || len(p.columnLabels) == 0 && !p.showLabels {
+		if isEvent {
+			return p.delegate.PrintObj(event, w)
+		}
+		return p.delegate.PrintObj(obj, w)
+	}
+	// the Table may be printed again, like by printWithEvents
+	table := copyRows(original)
+	// a watch event without columns prints with those of the last Table
+	if len(table.ColumnDefinitions) > 0 {
+		columns := append([]metav1.TableColumnDefinition{}, table.ColumnDefinitions...)
+		for _, label := range p.columnLabels {
+			parts := strings.Split(label, "/")
+			columns = append(columns, metav1.TableColumnDefinition{Name: strings.ToUpper(parts[len(parts)-1]), Type: "string"})
+		}
+		if p.showLabels {
+			columns = append(columns, metav1.TableColumnDefinition{Name: "Labels", Type: "string"})
+		}
+		table.ColumnDefinitions = columns
+	}
+	for i := range table.Rows {
+		row := &table.Rows[i]
+		var objectLabels map[string]string
+		if row.Object.Object != nil {
+			if m, err := meta.Accessor(row.Object.Object); err == nil {
+				objectLabels = m.GetLabels()
+			}
+		}
+		for _, key := range p.columnLabels {
+			row.Cells = append(row.Cells, cellEscaper.Replace(objectLabels[key]))
+		}
+		if p.showLabels {
+			row.Cells = append(row.Cells, cellEscaper.Replace(labels.FormatLabels(objectLabels)))
+		}
+	}
+	if isEvent {
+		return p.delegate.PrintObj(&metav1.WatchEvent{Type: event.Type, Object: runtime.RawExtension{Object: table}}, w)
+	}
+	return p.delegate.PrintObj(table, w)
+}
+
+// copyRows returns a copy of table whose rows can be changed. Cells may hold
+// values DeepCopy does not know, they are not copied.
+func copyRows(table *metav1.Table) *metav1.Table {
+	copied := *table
+	copied.Rows = make([]metav1.TableRow, len(table.Rows))
+	for i, row := range table.Rows {
+		row.Cells = append([]interface{}{}, row.Cells...)
+		copied.Rows[i] = row
+	}
+	return &copied
+}
+
+// highlightRow wraps every cell of row, as escaped by colorTable, in the
+// escape codes of class, on top of the color of the cell.
+func (t *colorTheme) highlightRow(row *metav1.TableRow, class string) {
+	if t == nil || len(t.codes[class]) == 0 {
+		return
+	}
+	start := fmt.Sprintf("<\x1b[%sm>", t.codes[class])
+	for j, cell := range row.Cells {
+		s, ok := cell.(string)
+		if !ok {
+			continue
+		}
+		// restore the highlight after the reset ending a colored cell
+		s = strings.Replace(s, colorReset, colorReset+start, -1)
+		row.Cells[j] = start + s + colorReset
+	}
+}
+
+// colorReset is the tag colorTable ends colored cells with.
+const colorReset = "<\x1b[0m>"
+
+// colorTag matches the tags colorTable wraps escape codes in.
+var colorTag = regexp.MustCompile("<(\x1b\\[[0-9;]*m)>")
+
+// cellUnescaper undoes the escaping of cellEscaper.
+var cellUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// colorWriter unwraps the escape codes and cells of colored tables in the
+// aligned lines written by a tabwriter.
+type colorWriter struct {
+	Delegate io.Writer
+	line     []byte
+}
+
+func (c *colorWriter) Write(p []byte) (int, error) {
+	c.line = append(c.line, p...)
+	i := bytes.LastIndexByte(c.line, '\n')
+	if i == -1 {
+		return len(p), nil
+	}
+	lines := colorTag.ReplaceAllString(string(c.line[:i+1]), "$1")
+	c.line = c.line[i+1:]
+	if _, err := io.WriteString(c.Delegate, cellUnescaper.Replace(lines)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
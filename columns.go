@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// addColumnsFlag binds --columns to o.Columns.
+func addColumnsFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().StringArrayVar(&o.Columns, "columns", o.Columns, "Comma separated list of column headers to print, in that order, including the columns otherwise only shown by -o wide (e.g. --columns NAME,STATUS,NODE,IP). Prefix the list with a kind to choose the columns of that kind only (e.g. --columns pods=NAME,IP --columns deployments=NAME,READY). Can be repeated.")
+}
+
+// columnSelection holds the columns requested with --columns, either for
+// every kind or for the kinds named in front of them.
+type columnSelection struct {
+	all    []string
+	byKind map[string][]string
+}
+
+// parseColumnSelection parses --columns values of the form NAME,STATUS or
+// pods=NAME,STATUS. A kind is named by its resource, its group qualified
+// resource or its kind, ignoring case.
+func parseColumnSelection(specs []string) (*columnSelection, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	s := &columnSelection{byKind: map[string][]string{}}
+	for _, spec := range specs {
+		kind, columns := "", spec
+		if i := strings.Index(spec, "="); i != -1 {
+			kind, columns = strings.ToLower(strings.TrimSpace(spec[:i])), spec[i+1:]
+			if len(kind) == 0 {
+				return nil, fmt.Errorf("--columns %q: missing kind before '='", spec)
+			}
+		}
+		var names []string
+		for _, name := range strings.Split(columns, ",") {
+			name = strings.TrimSpace(name)
+			if len(name) == 0 {
+				return nil, fmt.Errorf("--columns %q: empty column name", spec)
+			}
+			names = append(names, name)
+		}
+		if len(kind) == 0 {
+			s.all = names
+			continue
+		}
+		s.byKind[kind] = names
+	}
+	return s, nil
+}
+
+// columnsFor returns the columns requested for mapping, or nil when its
+// columns are printed unchanged.
+func (s *columnSelection) columnsFor(mapping *meta.RESTMapping) []string {
+	columns, _ := s.columnsOf(mapping)
+	return columns
+}
+
+// columnsOf returns the columns requested for mapping and whether they were
+// requested for its kind rather than for every kind.
+func (s *columnSelection) columnsOf(mapping *meta.RESTMapping) ([]string, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if mapping != nil {
+		for _, key := range []string{
+			mapping.Resource.Resource,
+			mapping.Resource.GroupResource().String(),
+			mapping.GroupVersionKind.Kind,
+			mapping.GroupVersionKind.GroupKind().String(),
+		} {
+			if columns, ok := s.byKind[strings.ToLower(key)]; ok {
+				return columns, true
+			}
+		}
+	}
+	return s.all, false
+}
+
+// Apply reduces table to the columns requested for mapping, in the order
+// they were requested. Selected priority columns are printed without -o wide.
+// The columns requested for every kind that table does not have are left out,
+// and it keeps its own columns when it has none of them; printedColumns
+// rejects the columns that no printed kind has.
+func (s *columnSelection) Apply(table *metav1.Table, mapping *meta.RESTMapping) error {
+	names, forKind := s.columnsOf(mapping)
+	if len(names) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, 0, len(names))
+	for _, name := range names {
+		index := -1
+		for i, column := range table.ColumnDefinitions {
+			if strings.EqualFold(column.Name, name) {
+				index = i
+				break
+			}
+		}
+		if index == -1 && !forKind {
+			continue
+		}
+		if index == -1 {
+			available := make([]string, 0, len(table.ColumnDefinitions))
+			for _, column := range table.ColumnDefinitions {
+				available = append(available, strings.ToUpper(column.Name))
+			}
+			kind := "this kind"
+			if mapping != nil {
+				kind = mapping.Resource.GroupResource().String()
+			}
+			return fmt.Errorf("unknown column %q for %s, available columns are: %s", name, kind, strings.Join(available, ","))
+		}
+		indexes = append(indexes, index)
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	columns := make([]metav1.TableColumnDefinition, 0, len(indexes))
+	for _, i := range indexes {
+		column := table.ColumnDefinitions[i]
+		column.Priority = 0
+		columns = append(columns, column)
+	}
+	table.ColumnDefinitions = columns
+	for i := range table.Rows {
+		cells := make([]interface{}, 0, len(indexes))
+		for _, index := range indexes {
+			var cell interface{}
+			if index < len(table.Rows[i].Cells) {
+				cell = table.Rows[i].Cells[index]
+			}
+			cells = append(cells, cell)
+		}
+		table.Rows[i].Cells = cells
+	}
+	return nil
+}
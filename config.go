@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/homedir"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+// configEnv names the configuration file, unless --config is given.
+const configEnv = "KGET_CONFIG"
+
+// configSettings are the settings of the global section of the configuration
+// file and of its per-context sections.
+type configSettings struct {
+	Output        string                  `json:"output,omitempty"`
+	Namespace     string                  `json:"namespace,omitempty"`
+	AllNamespaces *bool                   `json:"allNamespaces,omitempty"`
+	Color         string                  `json:"color,omitempty"`
+	Colors        string                  `json:"colors,omitempty"`
+	TimeFormat    string                  `json:"timeFormat,omitempty"`
+	Kinds         map[string]kindSettings `json:"kinds,omitempty"`
+}
+
+// kindSettings are the defaults of one kind, named like in --columns.
+type kindSettings struct {
+	Columns      []string `json:"columns,omitempty"`
+	LabelColumns []string `json:"labelColumns,omitempty"`
+}
+
+// kgetConfig is the configuration file, e.g.
+//
+//	output: wide
+//	kinds:
+//	  pods:
+//	    columns: [NAME, STATUS, NODE]
+//	    labelColumns: [app]
+//	contexts:
+//	  prod:
+//	    namespace: web
+//	    color: never
+//
+// timeFormat is relative, rfc3339 or a Go time layout. It applies to the AGE
+// column of every kind and to the ages of printer definitions.
+type kgetConfig struct {
+	configSettings
+	Contexts map[string]configSettings `json:"contexts,omitempty"`
+}
+
+// configScalars are the settings holding a single value, with the
+// environment variable overriding each of them.
+var configScalars = []struct {
+	name string
+	env  string
+	get  func(*configSettings) string
+}{
+	{"output", "KGET_OUTPUT", func(s *configSettings) string { return s.Output }},
+	{"namespace", "KGET_NAMESPACE", func(s *configSettings) string { return s.Namespace }},
+	{"allNamespaces", "KGET_ALL_NAMESPACES", func(s *configSettings) string {
+		if s.AllNamespaces == nil {
+			return ""
+		}
+		return strconv.FormatBool(*s.AllNamespaces)
+	}},
+	{"color", "KGET_COLOR", func(s *configSettings) string { return s.Color }},
+	{"colors", colorThemeEnv, func(s *configSettings) string { return s.Colors }},
+	{"timeFormat", "KGET_TIME_FORMAT", func(s *configSettings) string { return s.TimeFormat }},
+}
+
+// configSetting is the effective value of a setting and where it came from.
+type configSetting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// effectiveConfig holds the settings of one context, merged from the
+// environment, the section of the context and the global section.
+type effectiveConfig struct {
+	path     string
+	found    bool
+	context  string
+	settings []configSetting
+	// file is the configuration read, to merge the settings of other contexts
+	file *kgetConfig
+}
+
+// readConfig reads the configuration file named by --config, KGET_CONFIG or
+// ~/.config/kget/config.yaml and merges the settings of the current context.
+// The default file may be missing.
+func readConfig(f cmdutil.Factory, cmd *cobra.Command) (*effectiveConfig, error) {
+	c := &effectiveConfig{}
+	explicit := true
+	if flag := cmd.Flags().Lookup("config"); flag != nil && len(flag.Value.String()) > 0 {
+		c.path = flag.Value.String()
+	} else if path := os.Getenv(configEnv); len(path) > 0 {
+		c.path = path
+	} else {
+		c.path, explicit = filepath.Join(homedir.HomeDir(), ".config", "kget", "config.yaml"), false
+	}
+
+	if flag := cmd.Flags().Lookup("context"); flag != nil && len(flag.Value.String()) > 0 {
+		c.context = flag.Value.String()
+	} else {
+		raw, err := f.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return nil, err
+		}
+		c.context = raw.CurrentContext
+	}
+
+	config := &kgetConfig{}
+	data, err := ioutil.ReadFile(c.path)
+	switch {
+	case err == nil:
+		c.found = true
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", c.path, err)
+		}
+	case !os.IsNotExist(err) || explicit:
+		return nil, err
+	}
+	c.file = config
+	c.merge()
+	return c, nil
+}
+
+// forContext returns the settings of the context name merged from the same
+// configuration as c.
+func (c *effectiveConfig) forContext(name string) *effectiveConfig {
+	other := &effectiveConfig{path: c.path, found: c.found, context: name, file: c.file}
+	other.merge()
+	return other
+}
+
+// merge sets the settings of c from the environment, the section of its
+// context and the global section of its file.
+func (c *effectiveConfig) merge() {
+	config := c.file
+	global := &config.configSettings
+	contextSection, hasContext := config.Contexts[c.context]
+	contextSource := "context " + c.context
+	for _, scalar := range configScalars {
+		setting := configSetting{Name: scalar.name}
+		switch {
+		case len(os.Getenv(scalar.env)) > 0:
+			setting.Value, setting.Source = os.Getenv(scalar.env), "env "+scalar.env
+		case hasContext && len(scalar.get(&contextSection)) > 0:
+			setting.Value, setting.Source = scalar.get(&contextSection), contextSource
+		case len(scalar.get(global)) > 0:
+			setting.Value, setting.Source = scalar.get(global), "global"
+		default:
+			continue
+		}
+		c.settings = append(c.settings, setting)
+	}
+
+	kinds := map[string]bool{}
+	for kind := range global.Kinds {
+		kinds[kind] = true
+	}
+	for kind := range contextSection.Kinds {
+		kinds[kind] = true
+	}
+	sortedKinds := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		sortedKinds = append(sortedKinds, kind)
+	}
+	sort.Strings(sortedKinds)
+	for _, kind := range sortedKinds {
+		for _, field := range []struct {
+			name string
+			get  func(kindSettings) []string
+		}{
+			{"columns", func(k kindSettings) []string { return k.Columns }},
+			{"labelColumns", func(k kindSettings) []string { return k.LabelColumns }},
+		} {
+			setting := configSetting{Name: "kinds." + kind + "." + field.name}
+			if values := field.get(contextSection.Kinds[kind]); len(values) > 0 {
+				setting.Value, setting.Source = strings.Join(values, ","), contextSource
+			} else if values := field.get(global.Kinds[kind]); len(values) > 0 {
+				setting.Value, setting.Source = strings.Join(values, ","), "global"
+			} else {
+				continue
+			}
+			c.settings = append(c.settings, setting)
+		}
+	}
+}
+
+// value returns the effective value of the setting name.
+func (c *effectiveConfig) value(name string) (string, bool) {
+	for _, setting := range c.settings {
+		if setting.Name == name {
+			return setting.Value, true
+		}
+	}
+	return "", false
+}
+
+// kindValues returns the per-kind values of the field of the kinds settings
+// as --columns specs, kind=value.
+func (c *effectiveConfig) kindValues(field string) []string {
+	var specs []string
+	for _, setting := range c.settings {
+		if !strings.HasPrefix(setting.Name, "kinds.") || !strings.HasSuffix(setting.Name, "."+field) {
+			continue
+		}
+		kind := strings.TrimSuffix(strings.TrimPrefix(setting.Name, "kinds."), "."+field)
+		specs = append(specs, kind+"="+setting.Value)
+	}
+	return specs
+}
+
+// applyConfig uses the effective configuration for the flags of cmd that
+// were not given on the command line.
+func (o *GetOptions) applyConfig(f cmdutil.Factory, cmd *cobra.Command) error {
+	config, err := readConfig(f, cmd)
+	if err != nil {
+		return err
+	}
+	if value, ok := config.value("output"); ok {
+		if err := setFlagDefault(cmd, "output", value); err != nil {
+			return err
+		}
+	}
+	if value, ok := config.value("color"); ok {
+		if err := setFlagDefault(cmd, "color", value); err != nil {
+			return err
+		}
+	}
+	o.colorSpec, _ = config.value("colors")
+	if value, ok := config.value("timeFormat"); ok {
+		switch value {
+		case "relative":
+			timestampFormat = ""
+		case "rfc3339":
+			timestampFormat = time.RFC3339
+		default:
+			// a layout without any element, like "iso", prints itself
+			if layoutCheckTime.Format(value) == value {
+				return fmt.Errorf("invalid configuration of timeFormat %q: not relative, rfc3339 or a Go time layout like %q", value, time.RFC3339)
+			}
+			timestampFormat = value
+		}
+	}
+	o.config = config
+	// -n wins over the namespace policy of the configuration
+	if !o.ExplicitNamespace {
+		if value, ok := config.value("namespace"); ok {
+			o.Namespace = value
+		}
+		if value, ok := config.value("allNamespaces"); ok {
+			if err := setFlagDefault(cmd, "all-namespaces", value); err != nil {
+				return err
+			}
+		}
+	}
+	if flag := cmd.Flags().Lookup("columns"); flag != nil && !flag.Changed {
+		o.Columns = config.kindValues("columns")
+	}
+	if flag := cmd.Flags().Lookup("label-columns"); flag != nil && !flag.Changed {
+		// label columns are selected per kind like columns
+		if o.labelColumns, err = parseColumnSelection(config.kindValues("labelColumns")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layoutCheckTime differs from the reference time of Go layouts in every
+// element, for a layout to print differently.
+var layoutCheckTime = time.Date(1999, time.November, 28, 13, 14, 9, 0, time.UTC)
+
+// setFlagDefault sets the flag name of cmd to value, unless cmd has no such
+// flag or it was given on the command line.
+func setFlagDefault(cmd *cobra.Command, name, value string) error {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	if err := flag.Value.Set(value); err != nil {
+		return fmt.Errorf("invalid configuration of --%s %q: %v", name, value, err)
+	}
+	return nil
+}
+
+// NewConfigCommand returns the command showing the configuration.
+func NewConfigCommand(f cmdutil.Factory) *cobra.Command {
+	streams := genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "config demo",
+		Long:  "Show the configuration read from ~/.config/kget/config.yaml, or the file named by --config or " + configEnv + ".",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "view",
+		Short: "config view demo",
+		Long:  "Print the settings in effect for the current context and where each came from: an environment variable, the section of the context or the global section of the configuration file. Flags given on the command line override them all.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readConfig(f, cmd)
+			if err != nil {
+				return err
+			}
+			return config.Print(streams)
+		},
+	})
+	return cmd
+}
+
+// Print writes the settings as a table, settings left unset included.
+func (c *effectiveConfig) Print(streams genericclioptions.IOStreams) error {
+	file := c.path
+	if !c.found {
+		file += " (not found)"
+	}
+	fmt.Fprintf(streams.Out, "File:    %s\nContext: %s\n\n", file, c.context)
+
+	w := printers.GetNewTabWriter(streams.Out)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, scalar := range configScalars {
+		if _, ok := c.value(scalar.name); !ok {
+			fmt.Fprintf(w, "%s\t<unset>\tdefault\n", scalar.name)
+			continue
+		}
+		for _, setting := range c.settings {
+			if setting.Name == scalar.name {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Name, setting.Value, setting.Source)
+			}
+		}
+	}
+	for _, setting := range c.settings {
+		if strings.HasPrefix(setting.Name, "kinds.") {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Name, setting.Value, setting.Source)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+const testConfig = `output: wide
+namespace: global
+timeFormat: rfc3339
+kinds:
+  pods:
+    columns: [NAME, STATUS]
+    labelColumns: [app]
+  svc:
+    columns: [NAME]
+contexts:
+  prod:
+    namespace: web
+    color: never
+    kinds:
+      pods:
+        columns: [NAME, NODE]
+`
+
+func TestReadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		context  string
+		env      map[string]string
+		expected []configSetting
+	}{
+		{
+			name:    "global",
+			context: "dev",
+			expected: []configSetting{
+				{Name: "output", Value: "wide", Source: "global"},
+				{Name: "namespace", Value: "global", Source: "global"},
+				{Name: "timeFormat", Value: "rfc3339", Source: "global"},
+				{Name: "kinds.pods.columns", Value: "NAME,STATUS", Source: "global"},
+				{Name: "kinds.pods.labelColumns", Value: "app", Source: "global"},
+				{Name: "kinds.svc.columns", Value: "NAME", Source: "global"},
+			},
+		},
+		{
+			name:    "context over global",
+			context: "prod",
+			expected: []configSetting{
+				{Name: "output", Value: "wide", Source: "global"},
+				{Name: "namespace", Value: "web", Source: "context prod"},
+				{Name: "color", Value: "never", Source: "context prod"},
+				{Name: "timeFormat", Value: "rfc3339", Source: "global"},
+				{Name: "kinds.pods.columns", Value: "NAME,NODE", Source: "context prod"},
+				{Name: "kinds.pods.labelColumns", Value: "app", Source: "global"},
+				{Name: "kinds.svc.columns", Value: "NAME", Source: "global"},
+			},
+		},
+		{
+			name:    "environment over context",
+			context: "prod",
+			env:     map[string]string{"KGET_NAMESPACE": "env", "KGET_ALL_NAMESPACES": "true"},
+			expected: []configSetting{
+				{Name: "output", Value: "wide", Source: "global"},
+				{Name: "namespace", Value: "env", Source: "env KGET_NAMESPACE"},
+				{Name: "allNamespaces", Value: "true", Source: "env KGET_ALL_NAMESPACES"},
+				{Name: "color", Value: "never", Source: "context prod"},
+				{Name: "timeFormat", Value: "rfc3339", Source: "global"},
+				{Name: "kinds.pods.columns", Value: "NAME,NODE", Source: "context prod"},
+				{Name: "kinds.pods.labelColumns", Value: "app", Source: "global"},
+				{Name: "kinds.svc.columns", Value: "NAME", Source: "global"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, scalar := range configScalars {
+				t.Setenv(scalar.env, test.env[scalar.env])
+			}
+			cmd := &cobra.Command{}
+			cmd.Flags().String("config", path, "")
+			cmd.Flags().String("context", test.context, "")
+			config, err := readConfig(nil, cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config.settings, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, config.settings)
+			}
+			// the settings of another context are merged the same way
+			if other := config.forContext(test.context); !reflect.DeepEqual(other.settings, test.expected) {
+				t.Errorf("expected the settings of context %s to be %v, got %v", test.context, test.expected, other.settings)
+			}
+		})
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := ioutil.WriteFile(invalid, []byte("output: wide\ncolour: never\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.yaml")} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("config", path, "")
+			cmd.Flags().String("context", "dev", "")
+			if _, err := readConfig(nil, cmd); err == nil {
+				t.Errorf("expected an error reading %s", path)
+			}
+		})
+	}
+}
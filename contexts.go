@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// contextAnnotation records on objects printed as is which kubeconfig
+// context they were read from.
+const contextAnnotation = "kget.io/context"
+
+// contextResult holds what a single kubeconfig context returned.
+type contextResult struct {
+	context string
+	infos   []*resource.Info
+	err     error
+	// converter prints the infos with the custom resource definitions and
+	// printer definitions of the context
+	converter *tableConverter
+	// cancel ends the requests of the context
+	cancel context.CancelFunc
+}
+
+// runContexts queries every requested context concurrently and prints the
+// merged result. Errors of a context are reported without aborting the
+// others.
+func (o *GetOptions) runContexts(f cmdutil.Factory, args []string) error {
+	names := o.Contexts
+	if o.AllContexts {
+		config, err := f.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return err
+		}
+		names = make([]string, 0, len(config.Contexts))
+		for name := range config.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	results := make([]contextResult, len(names))
+	sem := make(chan struct{}, o.ContextConcurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = o.getWithTimeout(name, args)
+		}(i, name)
+	}
+	wg.Wait()
+
+	var allErrs []error
+	var infos []*resource.Info
+	var contexts []string
+	o.contextConverters = map[string]*tableConverter{}
+	for _, result := range results {
+		defer result.cancel()
+		if result.err != nil {
+			allErrs = append(allErrs, fmt.Errorf("context %q: %v", result.context, result.err))
+		}
+		if result.converter != nil {
+			o.contextConverters[result.context] = result.converter
+		}
+		infos = append(infos, result.infos...)
+		for range result.infos {
+			contexts = append(contexts, result.context)
+		}
+	}
+
+	// keep each resource together, ordered by context
+	sortedInfos := make([]*resource.Info, 0, len(infos))
+	sortedContexts := make([]string, 0, len(infos))
+	for _, i := range groupIndexesByResource(infos) {
+		sortedInfos = append(sortedInfos, infos[i])
+		sortedContexts = append(sortedContexts, contexts[i])
+	}
+
+	if err := o.printInfos(sortedInfos, sortedContexts); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// getWithTimeout lists the requested objects in name, giving up after
+// --context-timeout. The requests of a context timing out are canceled, and
+// it returns once they ended, for nothing to use o while the caller prints;
+// the caller cancels the others once it printed their objects.
+func (o *GetOptions) getWithTimeout(name string, args []string) contextResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan contextResult, 1)
+	go func() {
+		result := contextResult{context: name, cancel: cancel}
+		f := o.factoryForContext(ctx, name)
+		// resolve custom resources and printer definitions in the context
+		co := *o
+		var err error
+		if co.crdColumns, err = newCRDColumns(f, o.CRDFilenames); err != nil {
+			result.err = err
+			done <- result
+			return
+		}
+		if o.printers != nil {
+			if co.printers, err = o.printers.forContext(f); err != nil {
+				result.err = err
+				done <- result
+				return
+			}
+		}
+		namespace, explicitNamespace, err := f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			result.err = err
+			done <- result
+			return
+		}
+		// -n wins over the namespace of the configuration of the context
+		if !explicitNamespace && o.config != nil {
+			if value, ok := o.config.forContext(name).value("namespace"); ok {
+				namespace = value
+			}
+		}
+		if o.AllNamespaces {
+			explicitNamespace = false
+		}
+		result.infos, result.err = co.newResult(f, namespace, explicitNamespace, args).Infos()
+		result.converter = co.newTableConverter()
+		done <- result
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(o.ContextTimeout):
+		cancel()
+		// discovery is not canceled, but bounded by the timeout of the flags
+		<-done
+		return contextResult{context: name, err: fmt.Errorf("timed out after %v", o.ContextTimeout), cancel: cancel}
+	}
+}
+
+// factoryForContext returns a Factory talking to the cluster of the named
+// kubeconfig context, keeping every other flag given on the command line.
+// Its requests are canceled with ctx.
+func (o *GetOptions) factoryForContext(ctx context.Context, name string) cmdutil.Factory {
+	flags := genericclioptions.NewConfigFlags(true)
+	if o.configFlags != nil {
+		flags = cloneConfigFlags(o.configFlags)
+	}
+	flags.Context = &name
+	timeout := o.ContextTimeout.String()
+	flags.Timeout = &timeout
+	return cmdutil.NewFactory(cmdutil.NewMatchVersionFlags(&contextClientGetter{ConfigFlags: flags, ctx: ctx}))
+}
+
+// cloneConfigFlags returns ConfigFlags holding every flag of c, but not the
+// client configuration c loaded for its own context.
+func cloneConfigFlags(c *genericclioptions.ConfigFlags) *genericclioptions.ConfigFlags {
+	flags := genericclioptions.NewConfigFlags(true)
+	src, dst := reflect.ValueOf(c).Elem(), reflect.ValueOf(flags).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		// the unexported fields cache the client configuration
+		if dst.Field(i).CanSet() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return flags
+}
+
+// contextClientGetter is a RESTClientGetter whose clients send their requests
+// with ctx. Discovery keeps the client of ConfigFlags, bounded by its timeout.
+type contextClientGetter struct {
+	*genericclioptions.ConfigFlags
+	ctx context.Context
+}
+
+func (g *contextClientGetter) ToRESTConfig() (*rest.Config, error) {
+	config, err := g.ConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &contextRoundTripper{delegate: rt, ctx: g.ctx}
+	})
+	return config, nil
+}
+
+// contextRoundTripper sends requests with ctx.
+type contextRoundTripper struct {
+	delegate http.RoundTripper
+	ctx      context.Context
+}
+
+func (rt *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.delegate.RoundTrip(req.WithContext(rt.ctx))
+}
+
+// addContextColumn prepends a Context column holding context to table.
+func addContextColumn(table *metav1.Table, context string) {
+	table.ColumnDefinitions = append([]metav1.TableColumnDefinition{
+		{Name: "Context", Type: "string", Description: "The kubeconfig context the object was read from."},
+	}, table.ColumnDefinitions...)
+	for i := range table.Rows {
+		table.Rows[i].Cells = append([]interface{}{context}, table.Rows[i].Cells...)
+	}
+}
+
+// withContextAnnotation returns a copy of obj annotated with the context it
+// was read from.
+func withContextAnnotation(obj runtime.Object, context string) runtime.Object {
+	obj = obj.DeepCopyObject()
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return obj
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[contextAnnotation] = context
+	accessor.SetAnnotations(annotations)
+	return obj
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/klog"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+var crdVersions = []schema.GroupVersionResource{
+	{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"},
+	{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions"},
+}
+
+// printerColumn is an additionalPrinterColumn of a CustomResourceDefinition
+// with its JSONPath already parsed.
+type printerColumn struct {
+	definition metav1.TableColumnDefinition
+	parser     *jsonpath.JSONPath
+}
+
+// crdColumns resolves the additionalPrinterColumns of custom resources, either
+// from CustomResourceDefinition manifests supplied on the command line or by
+// reading the definition from the cluster.
+type crdColumns struct {
+	factory cmdutil.Factory
+	local   map[schema.GroupVersionKind][]printerColumn
+	// cache holds the columns read from the cluster, including misses, so
+	// every definition is requested at most once.
+	cache map[schema.GroupVersionKind][]printerColumn
+}
+
+// newCRDColumns returns a crdColumns that knows the definitions found in
+// filenames and falls back to reading definitions through f. f may be nil
+// when no cluster should be contacted.
+func newCRDColumns(f cmdutil.Factory, filenames []string) (*crdColumns, error) {
+	c := &crdColumns{
+		factory: f,
+		local:   map[schema.GroupVersionKind][]printerColumn{},
+		cache:   map[schema.GroupVersionKind][]printerColumn{},
+	}
+	for _, filename := range filenames {
+		if err := c.addFile(filename); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *crdColumns) addFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error decoding %s: %v", filename, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			err = obj.EachListItem(func(item runtime.Object) error {
+				return c.addCRD(item.(*unstructured.Unstructured), c.local)
+			})
+		} else {
+			err = c.addCRD(obj, c.local)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", filename, err)
+		}
+	}
+}
+
+// addCRD indexes the printer columns of every version served by crd into
+// index. Objects that are not CustomResourceDefinitions are ignored.
+func (c *crdColumns) addCRD(crd *unstructured.Unstructured, index map[schema.GroupVersionKind][]printerColumn) error {
+	if crd.GetKind() != "CustomResourceDefinition" {
+		return nil
+	}
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	if len(group) == 0 || len(kind) == 0 {
+		return fmt.Errorf("CustomResourceDefinition %q has no group or kind", crd.GetName())
+	}
+
+	// apiextensions.k8s.io/v1beta1 declares columns for all versions at the top level
+	topLevel, _, _ := unstructured.NestedSlice(crd.Object, "spec", "additionalPrinterColumns")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if version, found, _ := unstructured.NestedString(crd.Object, "spec", "version"); found {
+		versions = append(versions, map[string]interface{}{"name": version})
+	}
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		specs, found, _ := unstructured.NestedSlice(version, "additionalPrinterColumns")
+		if !found {
+			specs = topLevel
+		}
+		columns, err := parsePrinterColumns(specs)
+		if err != nil {
+			return fmt.Errorf("CustomResourceDefinition %q: %v", crd.GetName(), err)
+		}
+		index[schema.GroupVersionKind{Group: group, Version: name, Kind: kind}] = columns
+	}
+	return nil
+}
+
+func parsePrinterColumns(specs []interface{}) ([]printerColumn, error) {
+	columns := make([]printerColumn, 0, len(specs))
+	for _, s := range specs {
+		spec, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(spec, "name")
+		path, found, _ := unstructured.NestedString(spec, "jsonPath")
+		if !found {
+			path, _, _ = unstructured.NestedString(spec, "JSONPath")
+		}
+		parser := jsonpath.New(name).AllowMissingKeys(true)
+		if err := parser.Parse(fmt.Sprintf("{%s}", path)); err != nil {
+			return nil, fmt.Errorf("unrecognized column %q definition %q: %v", name, path, err)
+		}
+		column := printerColumn{parser: parser}
+		column.definition.Name = name
+		column.definition.Type, _, _ = unstructured.NestedString(spec, "type")
+		column.definition.Format, _, _ = unstructured.NestedString(spec, "format")
+		column.definition.Description, _, _ = unstructured.NestedString(spec, "description")
+		// manifests decoded from YAML carry numbers as float64
+		switch priority := spec["priority"].(type) {
+		case int64:
+			column.definition.Priority = int32(priority)
+		case float64:
+			column.definition.Priority = int32(priority)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// columnsFor returns the printer columns declared for gvk, or nil when gvk is
+// not a custom resource or its definition declares none.
+func (c *crdColumns) columnsFor(gvk schema.GroupVersionKind) []printerColumn {
+	if columns, ok := c.local[gvk]; ok {
+		return columns
+	}
+	if c.factory == nil || legacyscheme.Scheme.IsGroupRegistered(gvk.Group) {
+		return nil
+	}
+	if columns, ok := c.cache[gvk]; ok {
+		return columns
+	}
+	if err := c.fetch(gvk); err != nil {
+		klog.V(2).Infof("Unable to read the CustomResourceDefinition of %v, using generic columns: %v", gvk, err)
+	}
+	return c.cache[gvk]
+}
+
+// fetch reads the CustomResourceDefinition serving gvk from the cluster and
+// caches its columns.
+func (c *crdColumns) fetch(gvk schema.GroupVersionKind) error {
+	// remember the miss until we know better
+	c.cache[gvk] = nil
+
+	mapper, err := c.factory.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	client, err := c.factory.DynamicClient()
+	if err != nil {
+		return err
+	}
+	name := mapping.Resource.Resource + "." + gvk.Group
+	for _, gvr := range crdVersions {
+		crd, err := client.Resource(gvr).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return c.addCRD(crd, c.cache)
+	}
+	return nil
+}
+
+// ToTable builds a Table for a custom resource from the printer columns of
+// its definition. It reports false if obj has no such columns.
+func (c *crdColumns) ToTable(obj runtime.Object) (*metav1.Table, bool, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	var items []runtime.Object
+	switch t := obj.(type) {
+	case *unstructured.Unstructured:
+		items = []runtime.Object{t}
+	case *unstructured.UnstructuredList:
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+		items, _ = meta.ExtractList(t)
+	default:
+		return nil, false, nil
+	}
+
+	columns := c.columnsFor(gvk)
+	if len(columns) == 0 {
+		return nil, false, nil
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		},
+	}
+	for _, column := range columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, column.definition)
+	}
+	for _, item := range items {
+		u, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			return nil, false, fmt.Errorf("unexpected object %T in list of %v", item, gvk)
+		}
+		row := metav1.TableRow{
+			Object: runtime.RawExtension{Object: u},
+			Cells:  []interface{}{u.GetName()},
+		}
+		for _, column := range columns {
+			row.Cells = append(row.Cells, column.cell(u.Object))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	if m, err := meta.ListAccessor(obj); err == nil {
+		table.ResourceVersion = m.GetResourceVersion()
+		table.Continue = m.GetContinue()
+	} else if m, err := meta.Accessor(obj); err == nil {
+		table.ResourceVersion = m.GetResourceVersion()
+	}
+	return table, true, nil
+}
+
+// cell evaluates the column against obj, formatting dates as ages.
+func (c printerColumn) cell(obj map[string]interface{}) interface{} {
+	results, err := c.parser.FindResults(obj)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil
+	}
+	value := results[0][0].Interface()
+	switch c.definition.Type {
+	case "date":
+		s, ok := value.(string)
+		if !ok {
+			return value
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return s
+		}
+		return translateTimestampSince(metav1.NewTime(t))
+	case "string":
+		return fmt.Sprint(value)
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// addDiffFlag binds --diff to o.Diff.
+func addDiffFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().BoolVar(&o.Diff, "diff", o.Diff, "When watching, print the cells that changed in updated rows as their old and new value (e.g. 1/3 -> 2/3), with a leading EVENT column marking added, modified and deleted objects. Updates changing no printed cell are not printed.")
+}
+
+// rowDiffer remembers the rows last printed for every object of a watch, to
+// rewrite the rows of updates into what changed.
+type rowDiffer struct {
+	rows map[types.UID][]metav1.TableRow
+}
+
+func newRowDiffer() *rowDiffer {
+	return &rowDiffer{rows: map[types.UID][]metav1.TableRow{}}
+}
+
+// Diff rewrites the changed cells of table, holding the rows of the object
+// uid for an event of eventType, as "old -> new". It reports false when no
+// printed cell changed, as for a resync. Ages are not compared, they change
+// all the time.
+func (d *rowDiffer) Diff(eventType watch.EventType, uid types.UID, table *metav1.Table) bool {
+	if eventType == watch.Deleted {
+		delete(d.rows, uid)
+		return true
+	}
+	previous, ok := d.rows[uid]
+	// the cells of table are rewritten, keep a copy
+	rows := make([]metav1.TableRow, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, metav1.TableRow{Cells: append([]interface{}(nil), row.Cells...)})
+	}
+	d.rows[uid] = rows
+	if !ok {
+		return true
+	}
+
+	changed := len(previous) != len(table.Rows)
+	for i := range table.Rows {
+		if i >= len(previous) {
+			break
+		}
+		cells := table.Rows[i].Cells
+		for j, cell := range cells {
+			if j < len(table.ColumnDefinitions) && isAgeColumn(table.ColumnDefinitions[j]) {
+				continue
+			}
+			old := cellAt(previous[i].Cells, j)
+			if fmt.Sprint(old) == fmt.Sprint(cell) {
+				continue
+			}
+			cells[j] = fmt.Sprintf("%v -> %v", old, cell)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func isAgeColumn(column metav1.TableColumnDefinition) bool {
+	return column.Type == "date" || strings.EqualFold(column.Name, "Age")
+}
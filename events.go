@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// eventColumns are the columns of the Event table printed under each row.
+var eventColumns = &columnSelection{all: []string{"Last Seen", "Type", "Reason", "Count", "Message"}}
+
+// addWithEventsFlag binds --with-events to o.WithEvents.
+func addWithEventsFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().IntVar(&o.WithEvents, "with-events", o.WithEvents, "Print the last events of every object under its row, 3 unless a number is given (e.g. --with-events=10). The events are read with a single list of the namespace, and one of the events of cluster-scoped objects when such a kind is printed.")
+	cmd.Flags().Lookup("with-events").NoOptDefVal = "3"
+}
+
+// eventIndex holds events by the UID of the object they involve.
+type eventIndex map[types.UID][]*unstructured.Unstructured
+
+// add indexes the events of list, oldest first.
+func (index eventIndex) add(list *unstructured.UnstructuredList) {
+	for i := range list.Items {
+		event := &list.Items[i]
+		// the events of a list carry no kind of their own
+		event.SetAPIVersion("v1")
+		event.SetKind("Event")
+		uid, _, _ := unstructured.NestedString(event.Object, "involvedObject", "uid")
+		index[types.UID(uid)] = append(index[types.UID(uid)], event)
+	}
+	for _, events := range index {
+		sort.SliceStable(events, func(i, j int) bool {
+			return eventTime(events[i]).Before(eventTime(events[j]))
+		})
+	}
+}
+
+// eventsResource is the resource --with-events lists.
+var eventsResource = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// listEvents reads the events of the namespace queried, or of all
+// namespaces, into an index.
+func (o *GetOptions) listEvents(f cmdutil.Factory) (eventIndex, error) {
+	client, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	namespace := o.Namespace
+	if o.AllNamespaces {
+		namespace = ""
+	}
+	list, err := client.Resource(eventsResource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the events for --with-events: %v", err)
+	}
+	o.eventsClient = client
+	index := eventIndex{}
+	index.add(list)
+	return index, nil
+}
+
+// listClusterScopedEvents adds the events of cluster-scoped objects, like
+// those of Nodes recorded in the default namespace, to the index once a
+// cluster-scoped kind is printed. They are read from all namespaces, which a
+// user may not be allowed to list: that only warns.
+func (o *GetOptions) listClusterScopedEvents() error {
+	if o.AllNamespaces || o.eventsClient == nil || o.clusterEventsListed {
+		return nil
+	}
+	o.clusterEventsListed = true
+	list, err := o.eventsClient.Resource(eventsResource).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.namespace", "").String(),
+	})
+	if apierrors.IsForbidden(err) {
+		fmt.Fprintf(o.ErrOut, "warning: unable to list the events of cluster-scoped objects for --with-events: %v\n", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to list the events of cluster-scoped objects for --with-events: %v", err)
+	}
+	o.events.add(list)
+	return nil
+}
+
+// eventTime returns when an event was last seen.
+func eventTime(event *unstructured.Unstructured) time.Time {
+	for _, field := range [][]string{{"lastTimestamp"}, {"eventTime"}, {"metadata", "creationTimestamp"}} {
+		value, _, _ := unstructured.NestedString(event.Object, field...)
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// addEventRows holds the rows of table back until the end of the resource,
+// for printWithEvents to align them all.
+func (p *infoPrinter) addEventRows(table *metav1.Table) {
+	if p.eventRows == nil {
+		p.eventRows = &metav1.Table{ColumnDefinitions: table.ColumnDefinitions}
+	}
+	p.eventRows.Rows = append(p.eventRows.Rows, table.Rows...)
+}
+
+// printWithEvents prints table with the last events of the object of every
+// row under it. The rows are printed one at a time, the headers with the
+// first, with the widths of the columns of the whole table.
+func (p *infoPrinter) printWithEvents(table *metav1.Table) error {
+	if p.lastMapping != nil && p.lastMapping.Scope.Name() == meta.RESTScopeNameRoot {
+		if err := p.o.listClusterScopedEvents(); err != nil {
+			return err
+		}
+	}
+	// the printer of the resource prints the headers once, measure with another
+	measure, err := p.o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	w := p.o.newTableWriter(ioutil.Discard)
+	w.SetRememberedWidths(p.w.RememberedWidths())
+	// printers add the label columns to the rows they print
+	if err := measure.PrintObj(copyRows(table), w); err != nil {
+		return err
+	}
+	w.Flush()
+	p.w.SetRememberedWidths(w.RememberedWidths())
+
+	for _, row := range table.Rows {
+		if err := p.printer.PrintObj(&metav1.Table{ColumnDefinitions: table.ColumnDefinitions, Rows: []metav1.TableRow{row}}, p.w); err != nil {
+			return err
+		}
+		p.w.Flush()
+		m, err := meta.Accessor(row.Object.Object)
+		if err != nil {
+			continue
+		}
+		events := p.o.events[m.GetUID()]
+		if len(events) == 0 {
+			continue
+		}
+		if len(events) > p.o.WithEvents {
+			events = events[len(events)-p.o.WithEvents:]
+		}
+		if err := p.printEvents(p.separatorWriter, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printEvents prints events indented, with the Event table handler.
+func (p *infoPrinter) printEvents(out io.Writer, events []*unstructured.Unstructured) error {
+	table := &metav1.Table{}
+	for _, event := range events {
+		t, err := p.converter.ToTable(event)
+		if err != nil {
+			return err
+		}
+		table.ColumnDefinitions = t.ColumnDefinitions
+		table.Rows = append(table.Rows, t.Rows...)
+	}
+	if err := eventColumns.Apply(table, nil); err != nil {
+		return err
+	}
+	p.o.colorTheme.colorTable(table)
+
+	var rendered bytes.Buffer
+	w := p.o.newTableWriter(&rendered)
+	if err := printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, w); err != nil {
+		return err
+	}
+	w.Flush()
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(rendered.String(), "\n"), "\n") {
+		if _, err := io.WriteString(out, "    "+line); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
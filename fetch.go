@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// runConcurrently lists every group of resource arguments with its own
+// builder, running at most --concurrency requests at the same time. The group
+// whose objects arrive first is printed first, a chunk at a time as they are
+// listed, so one slow type does not hold back the others; the groups waiting
+// for their turn hold back a chunk each.
+func (o *GetOptions) runConcurrently(f cmdutil.Factory, groups [][]string) error {
+	infos := make([]chan *resource.Info, len(groups))
+	errs := make([]error, len(groups))
+	for i := range groups {
+		// an info is a chunk already, when the server prints a Table
+		infos[i] = make(chan *resource.Info, 1)
+	}
+	// ready receives the index of every group once it has something to print
+	ready := make(chan int, len(groups))
+	go func() {
+		sem := make(chan struct{}, o.Concurrency)
+		for i, group := range groups {
+			sem <- struct{}{}
+			go func(i int, args []string) {
+				defer func() { <-sem }()
+				defer close(infos[i])
+				visited := 0
+				err := o.newResult(f, o.Namespace, o.ExplicitNamespace, args).Visit(func(info *resource.Info, err error) error {
+					if err != nil {
+						return err
+					}
+					if visited == 0 {
+						ready <- i
+					}
+					visited++
+					infos[i] <- info
+					return nil
+				})
+				if err != nil {
+					errs[i] = explainExpiredContinue(err, visited)
+				}
+				if visited == 0 {
+					ready <- i
+				}
+			}(i, group)
+		}
+	}()
+
+	p := o.newInfoPrinter()
+	var allErrs []error
+	for range groups {
+		i := <-ready
+		printed := 0
+		for info := range infos[i] {
+			printed++
+			if err := p.printChunked(info, printed); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		if errs[i] != nil {
+			allErrs = append(allErrs, errs[i])
+		}
+	}
+	if err := p.Close(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// splitResourceArgs splits the resource arguments of a multi-type query into
+// one group of arguments per resource type, in the order they were requested.
+// It returns nil when the query names a single type or reads objects from
+// files.
+func (o *GetOptions) splitResourceArgs(args []string) [][]string {
+	if len(args) == 0 || len(o.Filenames) > 0 || len(o.Kustomize) > 0 {
+		return nil
+	}
+
+	var order []string
+	byType := map[string][]string{}
+	add := func(resourceType string, args ...string) {
+		if _, ok := byType[resourceType]; !ok {
+			order = append(order, resourceType)
+		}
+		byType[resourceType] = append(byType[resourceType], args...)
+	}
+
+	if strings.Contains(args[0], "/") {
+		// every argument is of the form type/name
+		for _, arg := range args {
+			parts := strings.SplitN(arg, "/", 2)
+			if len(parts) != 2 {
+				return nil
+			}
+			add(parts[0], arg)
+		}
+	} else {
+		for _, resourceType := range strings.Split(args[0], ",") {
+			if len(resourceType) == 0 {
+				return nil
+			}
+			add(resourceType, resourceType)
+		}
+		for _, resourceType := range order {
+			byType[resourceType] = append(byType[resourceType], args[1:]...)
+		}
+	}
+
+	if len(order) < 2 {
+		return nil
+	}
+	groups := make([][]string, 0, len(order))
+	for _, resourceType := range order {
+		groups = append(groups, byType[resourceType])
+	}
+	return groups
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func TestSplitResourceArgs(t *testing.T) {
+	tests := []struct {
+		args      []string
+		filenames []string
+		expected  [][]string
+	}{
+		{args: []string{"pods"}},
+		{args: []string{"pods", "web-0", "web-1"}},
+		{args: []string{"pods,svc"}, expected: [][]string{{"pods"}, {"svc"}}},
+		{args: []string{"pods,svc", "web"}, expected: [][]string{{"pods", "web"}, {"svc", "web"}}},
+		{args: []string{"svc,deploy.apps,svc"}, expected: [][]string{{"svc", "svc"}, {"deploy.apps"}}},
+		{args: []string{"pods,"}},
+		{args: []string{"pod/web-0", "svc/web", "pod/web-1"}, expected: [][]string{{"pod/web-0", "pod/web-1"}, {"svc/web"}}},
+		{args: []string{"pod/web-0", "pod/web-1"}},
+		{args: []string{"pod/web-0", "svc"}},
+		{args: []string{"pods,svc"}, filenames: []string{"pods.yaml"}},
+		{},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			o := &GetOptions{FilenameOptions: resource.FilenameOptions{Filenames: test.filenames}}
+			if groups := o.splitResourceArgs(test.args); !reflect.DeepEqual(groups, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, groups)
+			}
+		})
+	}
+}
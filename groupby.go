@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// addGroupByFlags binds --group-by, --aggregate and --summary to o.
+func addGroupByFlags(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().StringSliceVar(&o.GroupBy, "group-by", o.GroupBy, "Comma separated list of columns to group the rows of every kind by, printing one row with the COUNT of rows per group instead (e.g. --group-by NODE).")
+	cmd.Flags().StringSliceVar(&o.Aggregates, "aggregate", o.Aggregates, "Comma separated list of sum, min or max aggregations of integer columns added to the groups of --group-by (e.g. --aggregate 'sum(RESTARTS),max(RESTARTS)').")
+	cmd.Flags().BoolVar(&o.Summary, "summary", o.Summary, "If true, print a footer after every kind counting its rows by STATUS, e.g. \"42 pods: 38 Running, 3 Pending, 1 CrashLoopBackOff\".")
+}
+
+// aggregate is one of the aggregations requested with --aggregate.
+type aggregate struct {
+	function string
+	column   string
+}
+
+func (a aggregate) String() string {
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(a.function), strings.ToUpper(a.column))
+}
+
+// parseAggregates parses --aggregate values of the form sum(RESTARTS).
+func parseAggregates(specs []string) ([]aggregate, error) {
+	aggregates := make([]aggregate, 0, len(specs))
+	for _, spec := range specs {
+		start, end := strings.Index(spec, "("), strings.LastIndex(spec, ")")
+		if start <= 0 || end != len(spec)-1 || end <= start+1 {
+			return nil, fmt.Errorf("--aggregate %q: expected sum(COLUMN), min(COLUMN) or max(COLUMN)", spec)
+		}
+		a := aggregate{function: strings.ToLower(strings.TrimSpace(spec[:start])), column: strings.TrimSpace(spec[start+1 : end])}
+		switch a.function {
+		case "sum", "min", "max":
+		default:
+			return nil, fmt.Errorf("--aggregate %q: unknown function %q, expected sum, min or max", spec, a.function)
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, nil
+}
+
+// grouping accumulates the rows of one kind into the groups of --group-by.
+type grouping struct {
+	columns    []string
+	aggregates []aggregate
+
+	definitions []metav1.TableColumnDefinition
+	groups      map[string]*group
+	order       []string
+}
+
+type group struct {
+	key    []interface{}
+	count  int64
+	values []float64
+	found  []bool
+}
+
+func newGrouping(columns []string, aggregates []aggregate) *grouping {
+	return &grouping{columns: columns, aggregates: aggregates, groups: map[string]*group{}}
+}
+
+// Add adds the rows of table to their groups. Rows of a table without a
+// column of --group-by are grouped under <none>, and the columns of
+// --aggregate it does not have are left out of the aggregation;
+// printedColumns rejects the columns that no printed kind has.
+func (g *grouping) Add(table *metav1.Table) error {
+	keyIndexes := make([]int, 0, len(g.columns))
+	for _, name := range g.columns {
+		keyIndexes = append(keyIndexes, findColumn(table, name))
+	}
+	valueIndexes := make([]int, 0, len(g.aggregates))
+	for _, a := range g.aggregates {
+		i := findColumn(table, a.column)
+		if i == -1 {
+			valueIndexes = append(valueIndexes, i)
+			continue
+		}
+		if t := table.ColumnDefinitions[i].Type; t != "integer" && t != "number" {
+			return fmt.Errorf("--aggregate: column %s holds %ss, not numbers", strings.ToUpper(table.ColumnDefinitions[i].Name), t)
+		}
+		valueIndexes = append(valueIndexes, i)
+	}
+
+	if g.definitions == nil {
+		for j, i := range keyIndexes {
+			column := metav1.TableColumnDefinition{Name: g.columns[j], Type: "string"}
+			if i != -1 {
+				column = table.ColumnDefinitions[i]
+			}
+			column.Priority = 0
+			g.definitions = append(g.definitions, column)
+		}
+		g.definitions = append(g.definitions, metav1.TableColumnDefinition{Name: "Count", Type: "integer", Description: "The number of rows in the group."})
+		for _, a := range g.aggregates {
+			g.definitions = append(g.definitions, metav1.TableColumnDefinition{Name: a.String(), Type: "integer", Description: fmt.Sprintf("The %s of %s over the rows in the group.", a.function, a.column)})
+		}
+	}
+
+	for _, row := range table.Rows {
+		key := make([]interface{}, 0, len(keyIndexes))
+		parts := make([]string, 0, len(keyIndexes))
+		for _, i := range keyIndexes {
+			var cell interface{} = "<none>"
+			if i != -1 {
+				cell = cellAt(row.Cells, i)
+			}
+			key = append(key, cell)
+			parts = append(parts, fmt.Sprint(cell))
+		}
+		id := strings.Join(parts, "\x00")
+		grp, ok := g.groups[id]
+		if !ok {
+			grp = &group{key: key, values: make([]float64, len(g.aggregates)), found: make([]bool, len(g.aggregates))}
+			g.groups[id] = grp
+			g.order = append(g.order, id)
+		}
+		grp.count++
+		for j, i := range valueIndexes {
+			if i == -1 {
+				continue
+			}
+			n, ok := leadingNumber(fmt.Sprint(cellAt(row.Cells, i)))
+			if !ok {
+				continue
+			}
+			switch {
+			case !grp.found[j]:
+				grp.values[j] = n
+			case g.aggregates[j].function == "sum":
+				grp.values[j] += n
+			case g.aggregates[j].function == "min":
+				grp.values[j] = math.Min(grp.values[j], n)
+			case g.aggregates[j].function == "max":
+				grp.values[j] = math.Max(grp.values[j], n)
+			}
+			grp.found[j] = true
+		}
+	}
+	return nil
+}
+
+// Table returns the groups seen so far, largest first.
+func (g *grouping) Table() *metav1.Table {
+	table := &metav1.Table{ColumnDefinitions: g.definitions}
+	groups := make([]*group, 0, len(g.order))
+	for _, id := range g.order {
+		groups = append(groups, g.groups[id])
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].count > groups[j].count
+	})
+	for _, grp := range groups {
+		cells := append([]interface{}{}, grp.key...)
+		cells = append(cells, grp.count)
+		for j := range g.aggregates {
+			if !grp.found[j] {
+				cells = append(cells, "<none>")
+				continue
+			}
+			cells = append(cells, formatNumber(grp.values[j]))
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: cells})
+	}
+	return table
+}
+
+// formatNumber keeps whole numbers integers.
+func formatNumber(n float64) interface{} {
+	if n == math.Trunc(n) {
+		return int64(n)
+	}
+	return n
+}
+
+// summary counts the rows of one kind by their STATUS column.
+type summary struct {
+	total    int
+	statuses map[string]int
+	order    []string
+}
+
+func newSummary() *summary {
+	return &summary{statuses: map[string]int{}}
+}
+
+// Add counts the rows of table.
+func (s *summary) Add(table *metav1.Table) {
+	status := -1
+	for i, column := range table.ColumnDefinitions {
+		if strings.EqualFold(column.Name, "Status") {
+			status = i
+			break
+		}
+	}
+	for _, row := range table.Rows {
+		s.total++
+		if status == -1 {
+			continue
+		}
+		value := fmt.Sprint(cellAt(row.Cells, status))
+		if _, ok := s.statuses[value]; !ok {
+			s.order = append(s.order, value)
+		}
+		s.statuses[value]++
+	}
+}
+
+// Print writes the footer of the kind of mapping, if any row was counted.
+func (s *summary) Print(w io.Writer, mapping *meta.RESTMapping) error {
+	if s.total == 0 {
+		return nil
+	}
+	resource := "objects"
+	switch {
+	case mapping != nil && s.total == 1:
+		resource = strings.ToLower(mapping.GroupVersionKind.Kind)
+	case mapping != nil:
+		resource = mapping.Resource.Resource
+	case s.total == 1:
+		resource = "object"
+	}
+	statuses := append([]string{}, s.order...)
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return s.statuses[statuses[i]] > s.statuses[statuses[j]]
+	})
+	counts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		counts = append(counts, fmt.Sprintf("%d %s", s.statuses[status], status))
+	}
+	line := fmt.Sprintf("%d %s", s.total, resource)
+	if len(counts) > 0 {
+		line += ": " + strings.Join(counts, ", ")
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+// printedColumns remembers the columns of the printed kinds, for the flags
+// naming columns to reject only those that no printed kind has.
+type printedColumns struct {
+	names []string
+	seen  map[string]bool
+}
+
+// Add remembers the columns of table.
+func (c *printedColumns) Add(table *metav1.Table) {
+	if c.seen == nil {
+		c.seen = map[string]bool{}
+	}
+	for _, column := range table.ColumnDefinitions {
+		name := strings.ToUpper(column.Name)
+		if !c.seen[name] {
+			c.seen[name] = true
+			c.names = append(c.names, name)
+		}
+	}
+}
+
+// Check returns an error for the first of names that none of the kinds
+// printed so far has. Nothing is rejected before a kind was printed.
+func (c *printedColumns) Check(flag string, names ...string) error {
+	if len(c.seen) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if !c.seen[strings.ToUpper(name)] {
+			return fmt.Errorf("%s: unknown column %q, available columns are: %s", flag, name, strings.Join(c.names, ","))
+		}
+	}
+	return nil
+}
+
+// findColumn returns the index of the named column of table, ignoring case,
+// or -1 if table has no such column.
+func findColumn(table *metav1.Table, name string) int {
+	for i, column := range table.ColumnDefinitions {
+		if strings.EqualFold(column.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnIndex returns the index of the named column of table, ignoring case.
+func columnIndex(table *metav1.Table, name, flag string) (int, error) {
+	for i, column := range table.ColumnDefinitions {
+		if strings.EqualFold(column.Name, name) {
+			return i, nil
+		}
+	}
+	available := make([]string, 0, len(table.ColumnDefinitions))
+	for _, column := range table.ColumnDefinitions {
+		available = append(available, strings.ToUpper(column.Name))
+	}
+	return -1, fmt.Errorf("%s: unknown column %q, available columns are: %s", flag, name, strings.Join(available, ","))
+}
+
+func cellAt(cells []interface{}, i int) interface{} {
+	if i < len(cells) {
+		return cells[i]
+	}
+	return nil
+}
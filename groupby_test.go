@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseAggregates(t *testing.T) {
+	tests := []struct {
+		specs    []string
+		expected []aggregate
+		err      string
+	}{
+		{specs: []string{"sum(RESTARTS)"}, expected: []aggregate{{function: "sum", column: "RESTARTS"}}},
+		{specs: []string{"MAX( restarts )", "min(Ready)"}, expected: []aggregate{{function: "max", column: "restarts"}, {function: "min", column: "Ready"}}},
+		{specs: []string{"avg(RESTARTS)"}, err: "unknown function"},
+		{specs: []string{"sum()"}, err: "expected sum(COLUMN)"},
+		{specs: []string{"sum(RESTARTS"}, err: "expected sum(COLUMN)"},
+		{specs: []string{"(RESTARTS)"}, err: "expected sum(COLUMN)"},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.specs, ","), func(t *testing.T) {
+			aggregates, err := parseAggregates(test.specs)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(aggregates, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, aggregates)
+			}
+		})
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	pods := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Restarts", Type: "integer"},
+			{Name: "Node", Type: "string", Priority: 1},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"a", "Running", int64(1), "n1"}},
+			{Cells: []interface{}{"b", "Pending", int64(0), "n2"}},
+			{Cells: []interface{}{"c", "Running", "3 (5m ago)", "n2"}},
+			{Cells: []interface{}{"d", "Running", "<none>", "n1"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		columns    []string
+		aggregates []string
+		headers    []string
+		rows       [][]interface{}
+		err        string
+	}{
+		{
+			name:    "count",
+			columns: []string{"STATUS"},
+			headers: []string{"Status", "Count"},
+			rows:    [][]interface{}{{"Running", int64(3)}, {"Pending", int64(1)}},
+		},
+		{
+			name:       "aggregates",
+			columns:    []string{"node"},
+			aggregates: []string{"sum(RESTARTS)", "max(RESTARTS)", "min(RESTARTS)"},
+			headers:    []string{"Node", "Count", "SUM(RESTARTS)", "MAX(RESTARTS)", "MIN(RESTARTS)"},
+			rows:       [][]interface{}{{"n1", int64(2), int64(1), int64(1), int64(1)}, {"n2", int64(2), int64(3), int64(3), int64(0)}},
+		},
+		{
+			name:    "several columns",
+			columns: []string{"STATUS", "NODE"},
+			headers: []string{"Status", "Node", "Count"},
+			rows:    [][]interface{}{{"Running", "n1", int64(2)}, {"Pending", "n2", int64(1)}, {"Running", "n2", int64(1)}},
+		},
+		{
+			name:       "missing columns",
+			columns:    []string{"IP"},
+			aggregates: []string{"sum(READY)"},
+			headers:    []string{"IP", "Count", "SUM(READY)"},
+			rows:       [][]interface{}{{"<none>", int64(4), "<none>"}},
+		},
+		{
+			name:       "not a number",
+			columns:    []string{"STATUS"},
+			aggregates: []string{"sum(NAME)"},
+			err:        "holds strings, not numbers",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aggregates, err := parseAggregates(test.aggregates)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := newGrouping(test.columns, aggregates)
+			err = g.Add(pods)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			table := g.Table()
+			if headers := columnNames(table); !reflect.DeepEqual(headers, test.headers) {
+				t.Errorf("expected columns %v, got %v", test.headers, headers)
+			}
+			for _, column := range table.ColumnDefinitions {
+				if column.Priority != 0 {
+					t.Errorf("column %s has priority %d, expected it printed without -o wide", column.Name, column.Priority)
+				}
+			}
+			var rows [][]interface{}
+			for _, row := range table.Rows {
+				rows = append(rows, row.Cells)
+			}
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Errorf("expected rows %v, got %v", test.rows, rows)
+			}
+		})
+	}
+}
+
+func TestSummaryPrint(t *testing.T) {
+	pods := &meta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+	}
+	table := func(statuses ...string) *metav1.Table {
+		t := &metav1.Table{ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Status"}}}
+		for _, status := range statuses {
+			t.Rows = append(t.Rows, metav1.TableRow{Cells: []interface{}{"x", status}})
+		}
+		return t
+	}
+	tests := []struct {
+		name     string
+		tables   []*metav1.Table
+		mapping  *meta.RESTMapping
+		expected string
+	}{
+		{
+			name:     "statuses by count",
+			tables:   []*metav1.Table{table("Pending", "Running"), table("Running")},
+			mapping:  pods,
+			expected: "3 pods: 2 Running, 1 Pending\n",
+		},
+		{
+			name:     "one row",
+			tables:   []*metav1.Table{table("Running")},
+			mapping:  pods,
+			expected: "1 pod: 1 Running\n",
+		},
+		{
+			name:     "no status column",
+			tables:   []*metav1.Table{{ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}}, Rows: []metav1.TableRow{{Cells: []interface{}{"x"}}, {Cells: []interface{}{"y"}}}}},
+			expected: "2 objects\n",
+		},
+		{
+			name:   "no rows",
+			tables: []*metav1.Table{table()},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newSummary()
+			for _, table := range test.tables {
+				s.Add(table)
+			}
+			var out bytes.Buffer
+			if err := s.Print(&out, test.mapping); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestPrintedColumnsCheck(t *testing.T) {
+	c := &printedColumns{}
+	if err := c.Check("--where", "NODE"); err != nil {
+		t.Errorf("expected nothing rejected before a kind was printed, got %v", err)
+	}
+	c.Add(&metav1.Table{ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Data"}}})
+	c.Add(&metav1.Table{ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Type"}}})
+	if err := c.Check("--where", "data", "TYPE"); err != nil {
+		t.Errorf("expected the columns of every printed kind, got %v", err)
+	}
+	err := c.Check("--group-by", "NAME", "NODE")
+	if expected := `--group-by: unknown column "NODE", available columns are: NAME,DATA,TYPE`; err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
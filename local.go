@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// runLocal renders the objects read from -f or -k without contacting a
+// server.
+func (o *GetOptions) runLocal(f cmdutil.Factory, args []string) error {
+	if len(args) > 0 {
+		return resource.LocalResourceError
+	}
+	infos, err := f.NewBuilder().
+		Unstructured().
+		Local().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		ContinueOnError().
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return err
+	}
+	if err := o.completeLocalInfos(infos); err != nil {
+		return err
+	}
+	return o.printInfos(groupByResource(infos), nil)
+}
+
+// completeLocalInfos gives infos that were read without a server a mapping
+// guessed from their kind, and registers the printer columns of any
+// CustomResourceDefinition among them so that their custom resources print
+// with the right columns.
+func (o *GetOptions) completeLocalInfos(infos []*resource.Info) error {
+	mappings := map[schema.GroupVersionKind]*meta.RESTMapping{}
+	for _, info := range infos {
+		if u, ok := info.Object.(*unstructured.Unstructured); ok && u.GetKind() == "CustomResourceDefinition" {
+			if err := o.crdColumns.addCRD(u, o.crdColumns.local); err != nil {
+				return fmt.Errorf("%s: %v", info.Source, err)
+			}
+		}
+		if info.Mapping != nil {
+			continue
+		}
+		gvk := info.Object.GetObjectKind().GroupVersionKind()
+		mapping, ok := mappings[gvk]
+		if !ok {
+			resource, _ := meta.UnsafeGuessKindToResource(gvk)
+			mapping = &meta.RESTMapping{Resource: resource, GroupVersionKind: gvk}
+			mappings[gvk] = mapping
+		}
+		info.Mapping = mapping
+	}
+	return nil
+}
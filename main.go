@@ -1,33 +1,42 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/liggitt/tabwriter"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/duration"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	watchtools "k8s.io/client-go/tools/watch"
 	cliflag "k8s.io/component-base/cli/flag"
-	"k8s.io/kubectl/pkg/cmd/get"
+	"k8s.io/klog"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/term"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
-	"k8s.io/kubernetes/pkg/apis/apps"
-	"k8s.io/kubernetes/pkg/apis/batch"
-	"k8s.io/kubernetes/pkg/apis/core"
 	kprinters "k8s.io/kubernetes/pkg/printers"
 	printersinternal "k8s.io/kubernetes/pkg/printers/internalversion"
 )
@@ -54,11 +63,17 @@ func main() {
 	matchVersionKubeConfigFlags := cmdutil.NewMatchVersionFlags(kubeConfigFlags)
 	matchVersionKubeConfigFlags.AddFlags(cmd.PersistentFlags())
 
+	cmd.PersistentFlags().String("config", "", "Path to the kget configuration file. Defaults to $"+configEnv+", then ~/.config/kget/config.yaml.")
 	cmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
 	f := cmdutil.NewFactory(matchVersionKubeConfigFlags)
 
-	cmd.AddCommand(NewGetCommand(f))
+	cmd.AddCommand(NewGetCommand(f, kubeConfigFlags))
+	cmd.AddCommand(NewRenderCommand(f, kubeConfigFlags))
+	cmd.AddCommand(NewSnapshotCommand(f, kubeConfigFlags))
+	cmd.AddCommand(NewTreeCommand(f, kubeConfigFlags))
+	cmd.AddCommand(NewRelatedCommand(f, kubeConfigFlags))
+	cmd.AddCommand(NewConfigCommand(f))
 
 	err := cmd.Execute()
 	if err != nil {
@@ -67,7 +82,7 @@ func main() {
 }
 
 type GetOptions struct {
-	PrintFlags *get.PrintFlags
+	PrintFlags *PrintFlags
 	CmdParent  string
 
 	resource.FilenameOptions
@@ -77,6 +92,7 @@ type GetOptions struct {
 	WatchOnly bool
 
 	OutputWatchEvents bool
+	Diff              bool
 
 	LabelSelector     string
 	FieldSelector     string
@@ -84,23 +100,75 @@ type GetOptions struct {
 	Namespace         string
 	ExplicitNamespace bool
 
+	ServerPrint  bool
+	CRDFilenames []string
+
+	Contexts           []string
+	AllContexts        bool
+	ContextTimeout     time.Duration
+	ContextConcurrency int
+	Concurrency        int
+	ChunkSize          int64
+	Local              bool
+	Columns            []string
+	Where              string
+	GroupBy            []string
+	Aggregates         []string
+	Summary            bool
+	Color              string
+	Refresh            time.Duration
+	WithEvents         int
+	PrintersDirs       []string
+	BuiltinPrinters    []string
+
 	NoHeaders      bool
 	Sort           bool
 	IgnoreNotFound bool
 	Export         bool
 
+	crdColumns *crdColumns
+	printers   *userPrinters
+	// includeObject asks the server for the full object of every row
+	includeObject bool
+	// config is the configuration of the current context
+	config *effectiveConfig
+	// colorSpec is the color theme of KGET_COLORS or the configuration
+	colorSpec string
+	// labelColumns holds the -L label columns of every kind configured
+	labelColumns *columnSelection
+	columns      *columnSelection
+	where        whereExpr
+	// printedColumns holds the columns of the kinds printed so far
+	printedColumns printedColumns
+	aggregates     []aggregate
+	colorTheme     *colorTheme
+	events         eventIndex
+	// eventsClient lists the events of cluster-scoped objects, once
+	eventsClient        dynamic.Interface
+	clusterEventsListed bool
+	// contextConverters print the objects of every context of --contexts
+	contextConverters map[string]*tableConverter
+	configFlags       *genericclioptions.ConfigFlags
+
 	genericclioptions.IOStreams
 }
 
-func NewOptions() *GetOptions {
+func NewOptions(configFlags *genericclioptions.ConfigFlags) *GetOptions {
 	return &GetOptions{
-		PrintFlags: get.NewGetPrintFlags(),
-		IOStreams:  genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr},
+		PrintFlags:         NewPrintFlags(),
+		ServerPrint:        true,
+		ContextTimeout:     30 * time.Second,
+		ContextConcurrency: 5,
+		Concurrency:        4,
+		ChunkSize:          500,
+		Color:              "auto",
+		configFlags:        configFlags,
+		IOStreams:          genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr},
 	}
 }
 
-func NewGetCommand(f cmdutil.Factory) *cobra.Command {
-	o := NewOptions()
+func NewGetCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
 	cmd := &cobra.Command{
 		Use:   "get",
 		Short: "get demo",
@@ -124,6 +192,23 @@ func NewGetCommand(f cmdutil.Factory) *cobra.Command {
 	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	cmd.Flags().BoolVar(&o.Export, "export", o.Export, "If true, use 'export' for the resources.  Exported resources are stripped of cluster-specific information.")
 	cmd.Flags().MarkDeprecated("export", "This flag is deprecated and will be removed in future.")
+	cmd.Flags().BoolVar(&o.ServerPrint, "server-print", o.ServerPrint, "If true, have the server return the appropriate table output. Supports extension APIs and CRDs. Falls back to client-side printing when the server cannot produce a table.")
+	cmd.Flags().StringSliceVar(&o.CRDFilenames, "crd", o.CRDFilenames, "Path to a CustomResourceDefinition manifest whose additionalPrinterColumns are used to print its custom resources instead of reading the definition from the server. Can be repeated.")
+	cmd.Flags().StringSliceVar(&o.Contexts, "contexts", o.Contexts, "Comma separated list of kubeconfig contexts to query concurrently. Results are merged into one table with a leading CONTEXT column.")
+	cmd.Flags().BoolVar(&o.AllContexts, "all-contexts", o.AllContexts, "If present, query every context of the kubeconfig concurrently.")
+	cmd.Flags().DurationVar(&o.ContextTimeout, "context-timeout", o.ContextTimeout, "The maximum time to wait for each context when --contexts or --all-contexts is used.")
+	cmd.Flags().IntVar(&o.ContextConcurrency, "context-concurrency", o.ContextConcurrency, "The maximum number of contexts queried at the same time.")
+	cmd.Flags().IntVar(&o.Concurrency, "concurrency", o.Concurrency, "The maximum number of resource types listed at the same time when several types are requested. Set to 1 to list them one after another.")
+	cmd.Flags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize, "Return large lists in chunks rather than all at once, printing each chunk as soon as it arrives. Pass 0 to disable.")
+	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, render the objects given with -f or -k without contacting the API server.")
+	addColumnsFlag(cmd, o)
+	addWhereFlag(cmd, o)
+	addGroupByFlags(cmd, o)
+	addColorFlag(cmd, o)
+	addRefreshFlag(cmd, o)
+	addDiffFlag(cmd, o)
+	addWithEventsFlag(cmd, o)
+	addPrintersFlags(cmd, o)
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to get from a server.")
 
 	return cmd
@@ -135,85 +220,754 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 	if err != nil {
 		return err
 	}
+	if err := o.applyConfig(f, cmd); err != nil {
+		return err
+	}
 	if o.AllNamespaces {
 		o.ExplicitNamespace = false
 	}
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("--chunk-size must not be negative")
+	}
+	if o.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if len(o.Contexts) > 0 || o.AllContexts {
+		if o.Watch || o.WatchOnly {
+			return fmt.Errorf("--watch is not supported together with --contexts or --all-contexts")
+		}
+		if o.ContextConcurrency < 1 {
+			return fmt.Errorf("--context-concurrency must be at least 1")
+		}
+	}
+	crdFactory := f
+	if o.Local {
+		if o.Watch || o.WatchOnly || len(o.Contexts) > 0 || o.AllContexts {
+			return fmt.Errorf("--local cannot be combined with --watch, --contexts or --all-contexts")
+		}
+		if len(o.Filenames) == 0 && len(o.Kustomize) == 0 {
+			return fmt.Errorf("--local requires objects to be given with -f or -k")
+		}
+		crdFactory = nil
+	}
+	o.crdColumns, err = newCRDColumns(crdFactory, o.CRDFilenames)
+	if err != nil {
+		return err
+	}
+	if o.printers, err = loadUserPrinters(o.PrintersDirs, o.BuiltinPrinters); err != nil {
+		return err
+	}
+	if o.printers != nil && !o.Local {
+		if err := o.printers.resolveResources(f); err != nil {
+			return err
+		}
+	}
+	o.columns, err = parseColumnSelection(o.Columns)
+	if err != nil {
+		return err
+	}
+	if len(o.Where) > 0 {
+		if o.where, err = parseWhere(o.Where); err != nil {
+			return err
+		}
+	}
+	if o.aggregates, err = parseAggregates(o.Aggregates); err != nil {
+		return err
+	}
+	if len(o.aggregates) > 0 && len(o.GroupBy) == 0 {
+		return fmt.Errorf("--aggregate requires --group-by")
+	}
+	if len(o.GroupBy) > 0 || o.Summary {
+		if o.Watch || o.WatchOnly {
+			return fmt.Errorf("--group-by and --summary cannot be combined with --watch")
+		}
+		if len(o.GroupBy) > 0 && !o.PrintFlags.PrintsTables() {
+			return fmt.Errorf("--group-by requires a table output format")
+		}
+	}
+	if o.Diff {
+		if !o.Watch && !o.WatchOnly {
+			return fmt.Errorf("--diff requires --watch or --watch-only")
+		}
+		if !o.PrintFlags.PrintsTables() {
+			return fmt.Errorf("--diff requires a table output format")
+		}
+	}
+	if o.WithEvents < 0 {
+		return fmt.Errorf("--with-events must not be negative")
+	}
+	if o.WithEvents > 0 {
+		if o.Watch || o.WatchOnly || o.Local || len(o.Contexts) > 0 || o.AllContexts || len(o.GroupBy) > 0 || o.Refresh > 0 {
+			return fmt.Errorf("--with-events cannot be combined with --watch, --local, --contexts, --all-contexts, --group-by or --refresh")
+		}
+		if !o.PrintFlags.IsHumanReadable() {
+			return fmt.Errorf("--with-events requires the default or wide output format")
+		}
+	}
+	if o.Refresh < 0 {
+		return fmt.Errorf("--refresh must not be negative")
+	}
+	if o.Refresh > 0 {
+		if o.Watch || o.WatchOnly || o.Local || len(o.Contexts) > 0 || o.AllContexts || len(o.GroupBy) > 0 || o.Summary {
+			return fmt.Errorf("--refresh cannot be combined with --watch, --local, --contexts, --all-contexts, --group-by or --summary")
+		}
+		if !o.PrintFlags.IsHumanReadable() {
+			return fmt.Errorf("--refresh requires the default or wide output format")
+		}
+		if !term.IsTerminal(o.In) || !term.IsTerminal(o.Out) {
+			return fmt.Errorf("--refresh requires a terminal")
+		}
+	}
+	color, err := o.useColor()
+	if err != nil {
+		return err
+	}
+	if color && o.PrintFlags.IsHumanReadable() {
+		if o.colorTheme, err = parseColorTheme(o.colorSpec); err != nil {
+			return err
+		}
+	}
+	if o.Refresh > 0 && o.colorTheme == nil {
+		// rows are highlighted even without color
+		o.colorTheme = plainColorTheme()
+	}
+	o.PrintFlags.EscapedCells = o.colorTheme != nil
 	return nil
 }
 
 func (o *GetOptions) Run(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
-	r := f.NewBuilder().
+	if o.Refresh > 0 {
+		return o.runDashboard(f, args)
+	}
+	if o.Watch || o.WatchOnly {
+		return o.watch(f, cmd, args)
+	}
+
+	if o.Local {
+		return o.runLocal(f, args)
+	}
+	if len(o.Contexts) > 0 || o.AllContexts {
+		return o.runContexts(f, args)
+	}
+	if o.WithEvents > 0 {
+		var err error
+		if o.events, err = o.listEvents(f); err != nil {
+			return err
+		}
+	}
+	if groups := o.splitResourceArgs(args); len(groups) > 1 && o.Concurrency > 1 {
+		return o.runConcurrently(f, groups)
+	}
+
+	return o.printChunks(o.newResult(f, o.Namespace, o.ExplicitNamespace, args))
+}
+
+// printChunks prints the objects of r while they are listed, --chunk-size
+// objects at a time, so that only the current chunk is held in memory.
+// Column widths are remembered across chunks of the same resource.
+func (o *GetOptions) printChunks(r *resource.Result) error {
+	p := o.newInfoPrinter()
+	var allErrs []error
+	printed := 0
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		printed++
+		if err := p.printChunked(info, printed); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		return nil
+	})
+	if err := p.Close(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err != nil {
+		allErrs = append(allErrs, explainExpiredContinue(err, printed))
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// printChunked prints info, the printed-th object of its resource, and
+// flushes the output at the end of every chunk.
+func (p *infoPrinter) printChunked(info *resource.Info, printed int) error {
+	err := p.Print([]*resource.Info{info}, nil)
+	// a server-side Table holds a whole chunk
+	if recognizedTableVersions[info.Object.GetObjectKind().GroupVersionKind()] {
+		p.Flush()
+	} else if p.o.ChunkSize > 0 && printed%int(p.o.ChunkSize) == 0 {
+		p.Flush()
+	}
+	return err
+}
+
+// explainExpiredContinue replaces the error returned when the continue token
+// of a chunked list expired with one telling the user what happened.
+func explainExpiredContinue(err error, printed int) error {
+	agg, ok := err.(utilerrors.Aggregate)
+	if !ok {
+		agg = utilerrors.NewAggregate([]error{err})
+	}
+	errs := agg.Errors()
+	for i := range errs {
+		if apierrors.IsResourceExpired(errs[i]) {
+			errs[i] = fmt.Errorf("the list expired after %d objects were printed because it changed too much while being read in chunks, run the command again or use a larger --chunk-size: %v", printed, errs[i])
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// newResult builds the resource.Result listing the requested objects in
+// namespace through f.
+func (o *GetOptions) newResult(f cmdutil.Factory, namespace string, explicitNamespace bool, args []string) *resource.Result {
+	return f.NewBuilder().
 		Unstructured().
-		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
-		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		NamespaceParam(namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(explicitNamespace, &o.FilenameOptions).
 		LabelSelectorParam(o.LabelSelector).
 		FieldSelectorParam(o.FieldSelector).
 		ExportParam(o.Export).
 		ResourceTypeOrNameArgs(true, args...).
 		ContinueOnError().
-		//TransformRequests(o.transformRequests).
+		RequestChunksOf(o.ChunkSize).
+		TransformRequests(o.transformRequests).
 		Latest().
 		Flatten().
 		Do()
+}
 
-	infos, err := r.Infos()
-	if err != nil {
-		return err
+// printInfos prints infos grouped by resource. When contexts is not nil it
+// holds the kubeconfig context every info was read from, which is printed as
+// a leading column of tables and recorded on objects printed as is.
+func (o *GetOptions) printInfos(infos []*resource.Info, contexts []string) error {
+	p := o.newInfoPrinter()
+	err := p.Print(infos, contexts)
+	if closeErr := p.Close(); closeErr != nil {
+		return utilerrors.NewAggregate([]error{err, closeErr})
 	}
+	return err
+}
 
-	generator := kprinters.NewTableGenerator().With(printersinternal.AddHandlers).With(addHandlers)
+// infoPrinter prints batches of infos, keeping the per-resource printer,
+// column widths and separators across calls to Print.
+type infoPrinter struct {
+	o           *GetOptions
+	converter   *tableConverter
+	printTables bool
 
+	trackingWriter  *trackingWriterWrapper
+	separatorWriter *separatorWriterWrapper
+	w               *tabwriter.Writer
+	// out is w, or the underlying writer for formats that must not be aligned
+	out io.Writer
+
+	errs        sets.String
+	printer     kprinters.ResourcePrinter
+	lastMapping *meta.RESTMapping
+
+	// grouping and summary accumulate the rows of the current resource
+	grouping *grouping
+	summary  *summary
+	// eventRows accumulates the rows printed with --with-events
+	eventRows *metav1.Table
+}
+
+func (o *GetOptions) newInfoPrinter() *infoPrinter {
 	// track if we write any output
 	trackingWriter := &trackingWriterWrapper{Delegate: o.Out}
 	// output an empty line separating output
 	separatorWriter := &separatorWriterWrapper{Delegate: trackingWriter}
-	w := printers.GetNewTabWriter(separatorWriter)
+	w := o.newTableWriter(separatorWriter)
+	p := &infoPrinter{
+		o:               o,
+		converter:       o.newTableConverter(),
+		printTables:     o.PrintFlags.PrintsTables(),
+		trackingWriter:  trackingWriter,
+		separatorWriter: separatorWriter,
+		w:               w,
+		errs:            sets.NewString(),
+	}
+	switch {
+	case o.PrintFlags.StreamsRows():
+		// a stream of lines is never separated
+		p.out = trackingWriter
+	case p.printTables && !o.PrintFlags.IsHumanReadable():
+		p.out = separatorWriter
+	default:
+		p.out = p.w
+	}
+	return p
+}
 
+// Print writes infos, see printInfos. The output is flushed whenever the
+// resource changes; call Flush once all batches were printed.
+func (p *infoPrinter) Print(infos []*resource.Info, contexts []string) error {
 	allErrs := []error{}
-	errs := sets.NewString()
-	var printer kprinters.ResourcePrinter
-	var lastMapping *meta.RESTMapping
-	for _, info := range infos {
+	for ix, info := range infos {
 		mapping := info.Mapping
-		if shouldGetNewPrinterForMapping(printer, lastMapping, mapping) {
-			w.Flush()
-			w.SetRememberedWidths(nil)
+		if shouldGetNewPrinterForMapping(p.printer, p.lastMapping, mapping) {
+			if err := p.endResource(); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			p.w.SetRememberedWidths(nil)
 
 			// add linebreaks between resource groups (if there is more than one)
 			// when it satisfies all following 3 conditions:
 			// 1) it's not the first resource group
 			// 2) it has row header
 			// 3) we've written output since the last time we started a new set of headers
-			if lastMapping != nil && !o.NoHeaders && trackingWriter.Written > 0 {
-				separatorWriter.SetReady(true)
+			if p.lastMapping != nil && !p.o.NoHeaders && p.trackingWriter.Written > 0 {
+				p.separatorWriter.SetReady(true)
 			}
 
-			o.PrintFlags.SetKind(info.Object.GetObjectKind().GroupVersionKind().GroupKind())
-			printer, err = o.PrintFlags.ToPrinter()
+			var err error
+			p.o.PrintFlags.SetKind(mapping.GroupVersionKind.GroupKind())
+			if p.o.labelColumns != nil {
+				*p.o.PrintFlags.HumanReadableFlags.ColumnLabels = p.o.labelColumns.columnsFor(mapping)
+			}
+			p.printer, err = p.o.PrintFlags.ToPrinter()
 			if err != nil {
-				if !errs.Has(err.Error()) {
-					errs.Insert(err.Error())
+				if !p.errs.Has(err.Error()) {
+					p.errs.Insert(err.Error())
 					allErrs = append(allErrs, err)
 				}
 				continue
 			}
-			lastMapping = mapping
+			p.lastMapping = mapping
+			if len(p.o.GroupBy) > 0 {
+				p.grouping = newGrouping(p.o.GroupBy, p.o.aggregates)
+			}
+			if p.o.Summary {
+				p.summary = newSummary()
+			}
+		}
+
+		var table *metav1.Table
+		if p.printTables || p.o.where != nil || p.summary != nil {
+			var err error
+			converter := p.converter
+			if c, ok := p.o.contextConverters[contextOf(contexts, ix)]; ok {
+				converter = c
+			}
+			if table, err = p.o.toFilteredTable(converter, info.Object); err != nil {
+				return err
+			}
+			if p.summary != nil {
+				p.summary.Add(table)
+			}
+		}
+
+		if !p.printTables {
+			// objects printed as is are filtered on the rows of their Table
+			if table != nil && len(table.Rows) == 0 {
+				continue
+			}
+			obj := info.Object
+			if contexts != nil {
+				obj = withContextAnnotation(obj, contexts[ix])
+			}
+			if err := p.printer.PrintObj(obj, p.out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
+
+		if p.grouping != nil {
+			if contexts != nil {
+				addContextColumn(table, contexts[ix])
+				p.o.printedColumns.Add(table)
+			}
+			if err := p.grouping.Add(table); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.o.columns.Apply(table, mapping); err != nil {
+			return err
+		}
+		if contexts != nil {
+			addContextColumn(table, contexts[ix])
+		}
+		p.o.colorTheme.colorTable(table)
+		if p.o.events != nil {
+			p.addEventRows(table)
+			continue
+		}
+		if err := p.printer.PrintObj(table, p.out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// contextOf returns the context of the info at ix, or "" without contexts.
+func contextOf(contexts []string, ix int) string {
+	if contexts == nil {
+		return ""
+	}
+	return contexts[ix]
+}
+
+// toFilteredTable converts obj into a Table holding the rows matching --where.
+func (o *GetOptions) toFilteredTable(converter *tableConverter, obj runtime.Object) (*metav1.Table, error) {
+	table, err := converter.ToTable(obj)
+	if err != nil {
+		return nil, err
+	}
+	o.printedColumns.Add(table)
+	if o.where != nil {
+		if err := filterRows(o.where, table); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// checkColumns rejects the columns of --where, --columns, --group-by and
+// --aggregate that none of the kinds printed so far has.
+func (o *GetOptions) checkColumns() error {
+	if o.where != nil {
+		if err := o.printedColumns.Check("--where", o.where.columns()...); err != nil {
+			return err
+		}
+	}
+	if o.columns != nil {
+		if err := o.printedColumns.Check("--columns", o.columns.all...); err != nil {
+			return err
+		}
+	}
+	if err := o.printedColumns.Check("--group-by", o.GroupBy...); err != nil {
+		return err
+	}
+	for _, a := range o.aggregates {
+		if err := o.printedColumns.Check("--aggregate", a.column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered output.
+func (p *infoPrinter) Flush() {
+	p.w.Flush()
+}
+
+// Close ends the table being written and flushes the output. Print must not
+// be called afterwards.
+func (p *infoPrinter) Close() error {
+	if err := p.endResource(); err != nil {
+		return err
+	}
+	return p.o.checkColumns()
+}
+
+// endResource writes what is held back until all the objects of the current
+// resource were printed: the groups of --group-by, the end of the document,
+// the rows of --with-events and the --summary footer.
+func (p *infoPrinter) endResource() error {
+	var err error
+	if p.grouping != nil {
+		table := p.grouping.Table()
+		p.o.colorTheme.colorTable(table)
+		// the groups of every kind have the same columns, name the kind
+		if p.o.PrintFlags.IsHumanReadable() && p.lastMapping != nil && len(table.Rows) > 0 {
+			fmt.Fprintf(p.out, "%s:\n", p.lastMapping.Resource.GroupResource())
+		}
+		err = p.printer.PrintObj(table, p.out)
+		p.grouping = nil
+	}
+	if p.eventRows != nil {
+		if eventsErr := p.printWithEvents(p.eventRows); err == nil {
+			err = eventsErr
+		}
+		p.eventRows = nil
+	}
+	p.closeTable()
+	p.w.Flush()
+	if p.summary != nil {
+		// keep machine readable output parseable
+		out := p.o.ErrOut
+		if p.o.PrintFlags.IsHumanReadable() {
+			out = p.separatorWriter
 		}
-		table, err := ConvertResource(generator, info.Object)
+		if summaryErr := p.summary.Print(out, p.lastMapping); err == nil {
+			err = summaryErr
+		}
+		p.summary = nil
+	}
+	return err
+}
+
+// closeTable ends the document written by a documentPrinter, which spans
+// all the objects of one resource.
+func (p *infoPrinter) closeTable() {
+	if printer, ok := p.printer.(documentPrinter); ok {
+		if err := printer.Close(p.out); err != nil {
+			klog.V(2).Infof("Unable to end table: %v", err)
+		}
+	}
+}
+
+// documentPrinter is implemented by printers whose output for one resource
+// spans several calls to PrintObj and has to be ended.
+type documentPrinter interface {
+	Close(w io.Writer) error
+}
+
+// watch starts a client-side watch of one or more resources. Every object is
+// converted with ConvertResource and written through the same table printer,
+// so column widths are remembered between events.
+func (o *GetOptions) watch(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	r := o.watchResult(f, args)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	infos, err := r.Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		return fmt.Errorf("no resources found to watch")
+	}
+	if multipleGVKsRequested(infos) {
+		return fmt.Errorf("watch is only supported on individual resources and resource collections - more than 1 resource was found")
+	}
+
+	mapping := infos[0].ResourceMapping()
+	o.PrintFlags.SetKind(mapping.GroupVersionKind.GroupKind())
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	converter := o.newTableConverter()
+	printTables := o.PrintFlags.PrintsTables()
+	writer := o.newTableWriter(o.Out)
+	var out io.Writer = writer
+	if printTables && !o.PrintFlags.IsHumanReadable() {
+		out = o.Out
+	}
+	document, _ := printer.(documentPrinter)
+	if document != nil {
+		defer document.Close(out)
+	}
+	var differ *rowDiffer
+	if o.Diff {
+		differ = newRowDiffer()
+	}
+	printEvent := func(eventType watch.EventType, obj runtime.Object) error {
+		objToPrint := obj
+		if printTables || o.where != nil {
+			table, err := o.toFilteredTable(converter, obj)
+			if err != nil {
+				return err
+			}
+			// a watch prints a single kind
+			if err := o.checkColumns(); err != nil {
+				return err
+			}
+			// events of objects not matching --where are not printed
+			if o.where != nil && len(table.Rows) == 0 {
+				return nil
+			}
+			if printTables {
+				if err := o.columns.Apply(table, mapping); err != nil {
+					return err
+				}
+				if differ != nil && !differ.Diff(eventType, objectUID(obj), table) {
+					return nil
+				}
+				o.colorTheme.colorTable(table)
+				objToPrint = table
+			}
+		}
+		// the EVENT column tells deletions apart from the rows of a diff
+		if o.OutputWatchEvents || o.Diff {
+			objToPrint = &metav1.WatchEvent{Type: string(eventType), Object: runtime.RawExtension{Object: objToPrint}}
+		}
+		if err := printer.PrintObj(objToPrint, out); err != nil {
+			return fmt.Errorf("unable to output the provided object: %v", err)
+		}
+		// a Table object cannot be streamed, write one per event
+		if tableObject, ok := printer.(*TableObjectPrinter); ok {
+			if err := tableObject.Close(out); err != nil {
+				return fmt.Errorf("unable to output the provided object: %v", err)
+			}
+		}
+		return writer.Flush()
+	}
+
+	rv, objs, err := listForWatch(r)
+	if err != nil {
+		return err
+	}
+	// seen holds the last version of every object we know about, so that a
+	// re-list after an expired resourceVersion only emits what changed.
+	seen := map[types.UID]runtime.Object{}
+	for _, obj := range objs {
+		seen[objectUID(obj)] = obj
+		if o.WatchOnly {
+			continue
+		}
+		if err := printEvent(watch.Added, obj); err != nil {
+			return err
+		}
+	}
+
+	relist := func() error {
+		r = o.watchResult(f, args)
+		var objs []runtime.Object
+		rv, objs, err = listForWatch(r)
 		if err != nil {
 			return err
 		}
-		printer.PrintObj(table, w)
+		current := make(map[types.UID]runtime.Object, len(objs))
+		for _, obj := range objs {
+			uid := objectUID(obj)
+			current[uid] = obj
+			prev, ok := seen[uid]
+			switch {
+			case !ok:
+				err = printEvent(watch.Added, obj)
+			case resourceVersionOf(prev) != resourceVersionOf(obj):
+				err = printEvent(watch.Modified, obj)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for uid, prev := range seen {
+			if _, ok := current[uid]; ok {
+				continue
+			}
+			if err := printEvent(watch.Deleted, prev); err != nil {
+				return err
+			}
+		}
+		seen = current
+		return nil
 	}
-	w.Flush()
 
-	return utilerrors.NewAggregate(allErrs)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		backoff := newWatchBackoff()
+		for {
+			received := false
+			w, err := r.Watch(rv)
+			if err == nil {
+				_, err = watchtools.UntilWithoutRetry(ctx, w, func(e watch.Event) (bool, error) {
+					received = true
+					switch e.Type {
+					case watch.Error:
+						return false, apierrors.FromObject(e.Object)
+					case watch.Bookmark:
+						rv = resourceVersionOf(e.Object)
+						return false, nil
+					case watch.Deleted:
+						delete(seen, objectUID(e.Object))
+					default:
+						seen[objectUID(e.Object)] = e.Object
+					}
+					rv = resourceVersionOf(e.Object)
+					return false, printEvent(e.Type, e.Object)
+				})
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != watchtools.ErrWatchClosed && !apierrors.IsResourceExpired(err) && !apierrors.IsGone(err) {
+				return err
+			}
+			// a server closing every watch at once is not watched again in a
+			// tight loop
+			if received {
+				backoff = newWatchBackoff()
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff.Step()):
+			}
+			// when the server closed the watch, resume from the last version we saw
+			if err != watchtools.ErrWatchClosed {
+				klog.V(2).Infof("watch of resourceVersion %s expired, re-listing: %v", rv, err)
+				if err := relist(); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// newWatchBackoff returns the delays between watches restarted after the
+// previous one ended, reset once a watch received events.
+func newWatchBackoff() wait.Backoff {
+	return wait.Backoff{Duration: 500 * time.Millisecond, Factor: 2, Jitter: 0.1, Steps: math.MaxInt32, Cap: 30 * time.Second}
+}
+
+// watchResult builds the resource.Result used to list and watch a single
+// resource type.
+func (o *GetOptions) watchResult(f cmdutil.Factory, args []string) *resource.Result {
+	return f.NewBuilder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		FieldSelectorParam(o.FieldSelector).
+		ExportParam(o.Export).
+		ResourceTypeOrNameArgs(true, args...).
+		SingleResourceType().
+		Latest().
+		Do()
+}
+
+// listForWatch returns the objects in r together with the resourceVersion a
+// watch should be started from.
+func listForWatch(r *resource.Result) (string, []runtime.Object, error) {
+	obj, err := r.Object()
+	if err != nil {
+		return "", nil, err
+	}
+	if !meta.IsListType(obj) {
+		return resourceVersionOf(obj), []runtime.Object{obj}, nil
+	}
+	rv, err := meta.NewAccessor().ResourceVersion(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	objs, err := meta.ExtractList(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	return rv, objs, nil
+}
+
+func objectUID(obj runtime.Object) types.UID {
+	if m, err := meta.Accessor(obj); err == nil {
+		return m.GetUID()
+	}
+	return ""
+}
+
+func resourceVersionOf(obj runtime.Object) string {
+	if m, err := meta.Accessor(obj); err == nil {
+		return m.GetResourceVersion()
+	}
+	return ""
+}
+
+func newTableGenerator() *kprinters.HumanReadableGenerator {
+	return kprinters.NewTableGenerator().With(printersinternal.AddHandlers).With(addHandlers)
 }
 
 func addHandlers(h kprinters.PrintHandler) {
 	column := []metav1.TableColumnDefinition{
 		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
-		{Name: "Ready", Type: "string", Description: "The aggregate readiness state of this obj for accepting traffic."},
+		{Name: "Status", Type: "string", Description: "The status computed from the conditions, phase and generation of the object: Current, InProgress, Failed, Terminating or Unknown."},
+		{Name: "Reason", Type: "string", Description: "A brief CamelCase message indicating why the object is in its status."},
+		{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
 	}
 	h.TableHandler(column, printUnstructuredList)
 	h.TableHandler(column, printUnstructured)
@@ -235,25 +989,97 @@ func printUnstructured(obj *unstructured.Unstructured, options kprinters.Generat
 	row := metav1.TableRow{
 		Object: runtime.RawExtension{Object: obj},
 	}
-	row.Cells = append(row.Cells, obj.GetName(), translateTimestampSince(obj.GetCreationTimestamp()))
+	status, reason := computeStatus(obj)
+	if len(reason) == 0 {
+		reason = "<none>"
+	}
+	row.Cells = append(row.Cells, obj.GetName(), status, reason, translateTimestampSince(obj.GetCreationTimestamp()))
 	return []metav1.TableRow{row}, nil
 }
 
+// timestampFormat is the layout of the timestamps printed by
+// translateTimestampSince and formatAges, set by the timeFormat of the
+// configuration. Ages are printed when it is empty.
+var timestampFormat string
+
 // translateTimestampSince returns the elapsed time since timestamp in
-// human-readable approximation.
+// human-readable approximation, or timestamp in timestampFormat.
 func translateTimestampSince(timestamp metav1.Time) string {
 	if timestamp.IsZero() {
 		return "<unknown>"
 	}
+	if len(timestampFormat) > 0 {
+		return timestamp.Time.Format(timestampFormat)
+	}
 
 	return duration.HumanDuration(time.Since(timestamp.Time))
 }
 
 func shouldGetNewPrinterForMapping(printer printers.ResourcePrinter, lastMapping, mapping *meta.RESTMapping) bool {
-	return printer == nil || lastMapping == nil || mapping == nil || mapping.Resource != lastMapping.Resource
+	// every Table read from a file, as written by -o table-json, prints a resource of its own
+	return printer == nil || lastMapping == nil || mapping == nil || mapping.Resource != lastMapping.Resource ||
+		recognizedTableVersions[mapping.GroupVersionKind]
+}
+
+// groupByResource returns infos reordered so that objects of the same
+// resource are next to each other, keeping the order in which resources and
+// objects first appeared.
+func groupByResource(infos []*resource.Info) []*resource.Info {
+	grouped := make([]*resource.Info, 0, len(infos))
+	for _, i := range groupIndexesByResource(infos) {
+		grouped = append(grouped, infos[i])
+	}
+	return grouped
+}
+
+// groupIndexesByResource returns the indexes of infos in the order
+// groupByResource places them.
+func groupIndexesByResource(infos []*resource.Info) []int {
+	var order []string
+	groups := map[string][]int{}
+	for i, info := range infos {
+		key := mappingKey(info.Mapping)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	indexes := make([]int, 0, len(infos))
+	for _, key := range order {
+		indexes = append(indexes, groups[key]...)
+	}
+	return indexes
+}
+
+func mappingKey(mapping *meta.RESTMapping) string {
+	if mapping == nil {
+		return ""
+	}
+	return mapping.Resource.String()
+}
+
+func multipleGVKsRequested(infos []*resource.Info) bool {
+	if len(infos) < 2 {
+		return false
+	}
+	gvk := infos[0].Mapping.GroupVersionKind
+	for _, info := range infos {
+		if info.Mapping.GroupVersionKind != gvk {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *GetOptions) transformRequests(req *rest.Request) {
+	if !o.ServerPrint || !o.PrintFlags.PrintsTables() {
+		return
+	}
+	// resources with a printer definition are printed client-side
+	if o.printers != nil && o.printers.printsRequest(req.URL().Path) {
+		return
+	}
+
 	req.SetHeader("Accept", strings.Join([]string{
 		fmt.Sprintf("application/json;as=Table;v=%s;g=%s", metav1.SchemeGroupVersion.Version, metav1.GroupName),
 		fmt.Sprintf("application/json;as=Table;v=%s;g=%s", metav1beta1.SchemeGroupVersion.Version, metav1beta1.GroupName),
@@ -261,7 +1087,7 @@ func (o *GetOptions) transformRequests(req *rest.Request) {
 	}, ","))
 
 	// if sorting, ensure we receive the full object in order to introspect its fields via jsonpath
-	if o.Sort {
+	if o.Sort || o.includeObject || *o.PrintFlags.TableFlags.IncludeObject {
 		req.Param("includeObject", "Object")
 	}
 }
@@ -295,369 +1121,191 @@ func (s *separatorWriterWrapper) SetReady(state bool) {
 	s.Ready = state
 }
 
-func ConvertResource(generator *kprinters.HumanReadableGenerator, obj runtime.Object) (*metav1.Table, error) {
-	switch obj.GetObjectKind().GroupVersionKind().Kind {
-	case "Deployment":
-		v, ok := obj.(*apps.Deployment)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.Deployment)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "DeploymentList":
-		v, ok := obj.(*apps.DeploymentList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.DeploymentList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Statefulset":
-		v, ok := obj.(*apps.StatefulSet)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.StatefulSet)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "StatefulsetList":
-		v, ok := obj.(*apps.StatefulSetList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.StatefulSetList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Pod":
-		v, ok := obj.(*core.Pod)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Pod)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "PodList":
-		v, ok := obj.(*core.PodList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.PodList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Service":
-		v, ok := obj.(*core.Service)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Service)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ServiceList":
-		v, ok := obj.(*core.ServiceList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ServiceList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Secret":
-		v, ok := obj.(*core.Secret)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Secret)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "SecretList":
-		v, ok := obj.(*core.SecretList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.SecretList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Configmap":
-		v, ok := obj.(*core.ConfigMap)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ConfigMap)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ConfigmapList":
-		v, ok := obj.(*core.ConfigMapList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ConfigMapList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ReplicaSet":
-		v, ok := obj.(*apps.ReplicaSet)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.ReplicaSet)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ReplicaSetList":
-		v, ok := obj.(*apps.ReplicaSetList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, apps.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.ReplicaSetList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "DaemonSet":
-		v, ok := obj.(*apps.DaemonSet)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, batch.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.DaemonSet)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "DaemonSetList":
-		v, ok := obj.(*apps.DaemonSetList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*apps.DaemonSetList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Job":
-		v, ok := obj.(*batch.Job)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*batch.Job)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "JobList":
-		v, ok := obj.(*batch.JobList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, batch.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*batch.JobList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "CronJob":
-		v, ok := obj.(*batch.CronJob)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, batch.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*batch.CronJob)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "CronJobList":
-		v, ok := obj.(*batch.CronJobList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, batch.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*batch.CronJobList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Endpoints":
-		v, ok := obj.(*core.Endpoints)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Endpoints)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "EndpointsList":
-		v, ok := obj.(*core.EndpointsList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.EndpointsList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Node":
-		v, ok := obj.(*core.Node)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Node)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "NodeList":
-		v, ok := obj.(*core.NodeList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.NodeList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Event":
-		v, ok := obj.(*core.Event)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Event)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "EventList":
-		v, ok := obj.(*core.EventList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.EventList)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Namespace":
-		v, ok := obj.(*core.Namespace)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.Namespace)
-		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "NamespaceList":
-		v, ok := obj.(*core.NamespaceList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.NamespaceList)
+var recognizedTableVersions = map[schema.GroupVersionKind]bool{
+	metav1beta1.SchemeGroupVersion.WithKind("Table"): true,
+	metav1.SchemeGroupVersion.WithKind("Table"):      true,
+}
+
+// tableConverter turns the objects returned by the builder into Tables.
+type tableConverter struct {
+	generator   *kprinters.HumanReadableGenerator
+	crdColumns  *crdColumns
+	printers    *userPrinters
+	configFlags *genericclioptions.ConfigFlags
+}
+
+func (o *GetOptions) newTableConverter() *tableConverter {
+	return &tableConverter{
+		generator:  newTableGenerator(),
+		crdColumns: o.crdColumns,
+		printers:   o.printers,
+	}
+}
+
+// ToTable returns the Table the server rendered for obj. When the server or
+// resource did not produce one, kinds with a printer definition are printed
+// with it, custom resources with the columns of their CustomResourceDefinition
+// and everything else with ConvertResource.
+func (c *tableConverter) ToTable(obj runtime.Object) (*metav1.Table, error) {
+	table, err := decodeIntoTable(obj)
+	if err == nil {
+		formatAges(table)
+		return table, nil
+	}
+	klog.V(2).Infof("Unable to decode server response into a Table. Falling back to client-side printing: %v", err)
+
+	if c.printers != nil {
+		if table, ok, err := c.printers.ToTable(obj); ok || err != nil {
+			return table, err
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ServiceAccount":
-		v, ok := obj.(*core.ServiceAccount)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ServiceAccount)
+	}
+	if c.crdColumns != nil {
+		if table, ok, err := c.crdColumns.ToTable(obj); ok || err != nil {
+			return table, err
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ServiceAccountList":
-		v, ok := obj.(*core.ServiceAccountList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ServiceAccountList)
+	}
+	table, err = ConvertResource(c.generator, obj)
+	if err != nil {
+		return nil, err
+	}
+	formatAges(table)
+	return table, nil
+}
+
+// formatAges prints the AGE column of the server-side and built-in columns
+// of table in timestampFormat, from the creationTimestamp of the object of
+// every row. Rows without one keep their age.
+func formatAges(table *metav1.Table) {
+	if len(timestampFormat) == 0 {
+		return
+	}
+	age := findColumn(table, "Age")
+	if age == -1 {
+		return
+	}
+	for i := range table.Rows {
+		row := &table.Rows[i]
+		if row.Object.Object == nil || age >= len(row.Cells) {
+			continue
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Persistentvolume":
-		v, ok := obj.(*core.PersistentVolume)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.PersistentVolume)
+		m, err := meta.Accessor(row.Object.Object)
+		if err != nil {
+			continue
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "PersistentvolumeList":
-		v, ok := obj.(*core.PersistentVolumeList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.PersistentVolumeList)
+		if created := m.GetCreationTimestamp(); !created.IsZero() {
+			row.Cells[age] = translateTimestampSince(created)
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Persistentvolumeclaim":
-		v, ok := obj.(*core.PersistentVolumeClaim)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.PersistentVolumeClaim)
+	}
+}
+
+// decodeIntoTable converts a server-side Table received as unstructured
+// content into a metav1.Table, decoding the object embedded in each row.
+func decodeIntoTable(obj runtime.Object) (*metav1.Table, error) {
+	if !recognizedTableVersions[obj.GetObjectKind().GroupVersionKind()] {
+		return nil, fmt.Errorf("attempt to decode non-Table object")
+	}
+	unstr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("attempt to decode non-Unstructured object")
+	}
+	table := &metav1.Table{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstr.Object, table); err != nil {
+		return nil, err
+	}
+	for i := range table.Rows {
+		row := &table.Rows[i]
+		if row.Object.Raw == nil || row.Object.Object != nil {
+			continue
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "PersistentvolumeclaimList":
-		v, ok := obj.(*core.PersistentVolumeClaimList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.PersistentVolumeClaimList)
+		converted, err := runtime.Decode(unstructured.UnstructuredJSONScheme, row.Object.Raw)
+		if err != nil {
+			return nil, err
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "Resourcequota":
-		v, ok := obj.(*core.ResourceQuota)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
+		row.Object.Object = converted
+	}
+	return table, nil
+}
+
+// ConvertResource generates a Table for obj with the handlers registered on
+// generator. Unstructured objects whose kind is known to legacyscheme are
+// defaulted and converted to their internal type first, so that every handler
+// registered by printersinternal.AddHandlers applies; anything else is printed
+// as is. The Table keeps the priority columns, printers drop them unless asked
+// for a wide output.
+func ConvertResource(generator *kprinters.HumanReadableGenerator, obj runtime.Object) (*metav1.Table, error) {
+	if internal, ok := toInternalObject(obj); ok {
+		table, err := generateInternalTable(generator, internal)
+		if err == nil {
+			// rows must not expose the internal object, it cannot be serialized
+			list, isList := obj.(*unstructured.UnstructuredList)
+			for i := range table.Rows {
+				if isList && len(list.Items) == len(table.Rows) {
+					table.Rows[i].Object = runtime.RawExtension{Object: &list.Items[i]}
+				} else {
+					table.Rows[i].Object = runtime.RawExtension{Object: obj}
+				}
 			}
-			v = obj.(*core.ResourceQuota)
+			return table, nil
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	case "ResourcequotaList":
-		v, ok := obj.(*core.ResourceQuotaList)
-		if !ok {
-			obj, err := legacyscheme.Scheme.ConvertToVersion(obj, core.SchemeGroupVersion)
-			if err != nil {
-				return nil, err
-			}
-			v = obj.(*core.ResourceQuotaList)
+		klog.V(4).Infof("Unable to print %v with its internal printer, falling back to generic columns: %v", obj.GetObjectKind().GroupVersionKind(), err)
+	}
+	return generator.GenerateTable(obj, kprinters.GenerateOptions{Wide: true})
+}
+
+// generateInternalTable generates the Table of an internal object. The
+// internal handlers expect objects as a server returns them; a panic on a
+// partial object, like a ReplicationController without a template, is
+// returned as an error.
+func generateInternalTable(generator *kprinters.HumanReadableGenerator, obj runtime.Object) (table *metav1.Table, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			table, err = nil, fmt.Errorf("internal printer failed: %v", r)
 		}
-		return generator.GenerateTable(v, kprinters.GenerateOptions{})
-	default:
-		return generator.GenerateTable(obj, kprinters.GenerateOptions{})
+	}()
+	return generator.GenerateTable(obj, kprinters.GenerateOptions{Wide: true})
+}
+
+// equivalentKinds maps kinds whose API group is not installed in legacyscheme
+// onto a registered group version that serves the same schema.
+var equivalentKinds = map[schema.GroupVersionKind]schema.GroupVersionKind{
+	{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}:      {Group: "extensions", Version: "v1beta1", Kind: "Ingress"},
+	{Group: "networking.k8s.io", Version: "v1beta1", Kind: "IngressList"}:  {Group: "extensions", Version: "v1beta1", Kind: "IngressList"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}:     {Group: "extensions", Version: "v1beta1", Kind: "NetworkPolicy"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicyList"}: {Group: "extensions", Version: "v1beta1", Kind: "NetworkPolicyList"},
+}
+
+// toInternalObject converts an unstructured object into the internal type
+// registered for its kind in legacyscheme. It reports false when obj is not
+// unstructured or its kind is not registered.
+func toInternalObject(obj runtime.Object) (runtime.Object, bool) {
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		return nil, false
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if equivalent, ok := equivalentKinds[gvk]; ok {
+		obj = obj.DeepCopyObject()
+		obj.GetObjectKind().SetGroupVersionKind(equivalent)
+		gvk = equivalent
+	}
+	if !legacyscheme.Scheme.Recognizes(gvk) {
+		return nil, false
+	}
+	versioned, err := legacyscheme.Scheme.New(gvk)
+	if err != nil {
+		klog.V(4).Infof("Unable to create %v: %v", gvk, err)
+		return nil, false
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(runtime.Unstructured).UnstructuredContent(), versioned); err != nil {
+		klog.V(4).Infof("Unable to decode %v: %v", gvk, err)
+		return nil, false
+	}
+	// objects read from local files were never defaulted by a server
+	legacyscheme.Scheme.Default(versioned)
+	internalGV := schema.GroupVersion{Group: gvk.Group, Version: runtime.APIVersionInternal}
+	internal, err := legacyscheme.Scheme.ConvertToVersion(versioned, internalGV)
+	if err != nil {
+		klog.V(4).Infof("Unable to convert %v to its internal version: %v", gvk, err)
+		return nil, false
 	}
+	return internal, true
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	printersinternal "k8s.io/kubernetes/pkg/printers/internalversion"
+)
+
+// handlerRecorder records the types printersinternal.AddHandlers registers
+// handlers for.
+type handlerRecorder struct {
+	types []reflect.Type
+}
+
+func (r *handlerRecorder) TableHandler(columns []metav1.TableColumnDefinition, printFunc interface{}) error {
+	r.types = append(r.types, reflect.TypeOf(printFunc).In(0))
+	return nil
+}
+
+// registeredKinds returns the external kinds of the internal types
+// printersinternal.AddHandlers registers handlers for, lists excluded. The
+// external version is the preferred version of client-go serving the kind.
+func registeredKinds(t *testing.T) []schema.GroupVersionKind {
+	recorder := &handlerRecorder{}
+	printersinternal.AddHandlers(recorder)
+
+	var kinds []schema.GroupVersionKind
+	for _, typ := range recorder.types {
+		var gvk schema.GroupVersionKind
+		ok := false
+		// handlers of external types, like metav1.Status, need no conversion
+		if obj, isObject := reflect.New(typ.Elem()).Interface().(runtime.Object); isObject {
+			if gvks, _, err := clientgoscheme.Scheme.ObjectKinds(obj); err == nil {
+				gvk, ok = gvks[0], true
+			}
+		}
+		if !ok {
+			gvk, ok = externalKind(path.Base(typ.Elem().PkgPath()), strings.TrimSuffix(typ.Elem().Name(), "List"))
+		}
+		if !ok {
+			t.Errorf("no external version of %v", typ)
+			continue
+		}
+		if !containsKind(kinds, gvk) {
+			kinds = append(kinds, gvk)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	return kinds
+}
+
+// externalKind finds kind in the group of the internal API package pkg, named
+// like the group without its domain.
+func externalKind(pkg, kind string) (schema.GroupVersionKind, bool) {
+	for _, gv := range clientgoscheme.Scheme.PrioritizedVersionsAllGroups() {
+		group := strings.SplitN(gv.Group, ".", 2)[0]
+		if pkg == "core" && len(gv.Group) > 0 || pkg != "core" && group != pkg {
+			continue
+		}
+		if gvk := gv.WithKind(kind); clientgoscheme.Scheme.Recognizes(gvk) {
+			return gvk, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
+
+func containsKind(kinds []schema.GroupVersionKind, gvk schema.GroupVersionKind) bool {
+	for _, kind := range kinds {
+		if kind == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+// genericColumns are the columns of the kinds without a handler of their own.
+var genericColumns = []string{"Name", "Status", "Reason", "Age"}
+
+// genericKinds are the kinds printersinternal has handlers for that are
+// printed with the generic columns, because the install packages of their
+// groups are not vendored and legacyscheme cannot convert them.
+var genericKinds = map[schema.GroupKind]bool{
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                     true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:     true,
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                              true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+}
+
+func columnNames(table *metav1.Table) []string {
+	names := make([]string, 0, len(table.ColumnDefinitions))
+	for _, column := range table.ColumnDefinitions {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+func TestConvertResourceRegisteredKinds(t *testing.T) {
+	kinds := registeredKinds(t)
+	// the kinds legacyscheme prints as the kinds of another group
+	for gvk := range equivalentKinds {
+		if !strings.HasSuffix(gvk.Kind, "List") && !containsKind(kinds, gvk) {
+			kinds = append(kinds, gvk)
+		}
+	}
+
+	generator := newTableGenerator()
+	for _, gvk := range kinds {
+		apiVersion, kind := gvk.GroupVersion().String(), gvk.Kind
+		item := func(name string) unstructured.Unstructured {
+			u := unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name":              name,
+					"namespace":         "default",
+					"creationTimestamp": "2020-01-01T00:00:00Z",
+				},
+			}}
+			// a server always returns the template of a ReplicationController
+			if kind == "ReplicationController" {
+				unstructured.SetNestedMap(u.Object, map[string]interface{}{}, "spec", "template")
+			}
+			return u
+		}
+		single := item("test-0")
+		tests := []struct {
+			name  string
+			obj   runtime.Object
+			names []string
+		}{
+			{
+				name:  gvk.String(),
+				obj:   &single,
+				names: []string{"test-0"},
+			},
+			{
+				name: gvk.GroupVersion().WithKind(kind + "List").String(),
+				obj: &unstructured.UnstructuredList{
+					Object: map[string]interface{}{"apiVersion": apiVersion, "kind": kind + "List"},
+					Items:  []unstructured.Unstructured{item("test-0"), item("test-1")},
+				},
+				names: []string{"test-0", "test-1"},
+			},
+		}
+		// some kinds, like Status, have no list
+		if !clientgoscheme.Scheme.Recognizes(gvk.GroupVersion().WithKind(kind + "List")) {
+			tests = tests[:1]
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				// kinds of groups legacyscheme lacks are printed with generic columns
+				_, internal := toInternalObject(test.obj)
+				if installed := legacyscheme.Scheme.IsGroupRegistered(gvk.Group) || equivalentKinds[gvk] != (schema.GroupVersionKind{}); installed != internal {
+					t.Errorf("converted to an internal object: %v, group installed in legacyscheme: %v", internal, installed)
+				}
+				table, err := ConvertResource(generator, test.obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(table.Rows) != len(test.names) {
+					t.Fatalf("expected %d rows, got %d", len(test.names), len(table.Rows))
+				}
+				if generic := reflect.DeepEqual(columnNames(table), genericColumns); generic != genericKinds[gvk.GroupKind()] {
+					t.Errorf("printed with the generic columns: %v, expected: %v", generic, genericKinds[gvk.GroupKind()])
+				}
+				for i, row := range table.Rows {
+					if len(row.Cells) != len(table.ColumnDefinitions) {
+						t.Errorf("row %d has %d cells for %d columns", i, len(row.Cells), len(table.ColumnDefinitions))
+					}
+					m, err := meta.Accessor(row.Object.Object)
+					if err != nil {
+						t.Fatalf("row %d: %v", i, err)
+					}
+					if m.GetName() != test.names[i] {
+						t.Errorf("row %d holds object %q, expected %q", i, m.GetName(), test.names[i])
+					}
+				}
+			})
+		}
+	}
+}
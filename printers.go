@@ -0,0 +1,399 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/klog"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	kprinters "k8s.io/kubernetes/pkg/printers"
+)
+
+// printerDefinitionVersion is the apiVersion of printer definition files.
+const printerDefinitionVersion = "kget.io/v1"
+
+// addPrintersFlags binds --printers and --builtin-printers to o.
+func addPrintersFlags(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().StringSliceVar(&o.PrintersDirs, "printers", o.PrintersDirs, "Directory of printer definitions, read after those of ~/.kget/printers. A definition declares the columns of a kind with JSONPath expressions and replaces its built-in or server-side columns, e.g.\napiVersion: kget.io/v1\nkind: Printer\nspec:\n  group: example.com\n  kind: Widget\n  columns:\n  - name: Ready\n    jsonPath: .status.readyReplicas\n    type: integer\n  - name: Hosts\n    jsonPath: .spec.rules[*].host\n    format: join\n  - name: Age\n    jsonPath: .metadata.creationTimestamp\n    format: age\nThe formats are age, quantity, join and map-keys.")
+	cmd.Flags().StringSliceVar(&o.BuiltinPrinters, "builtin-printers", o.BuiltinPrinters, "Comma separated list of kinds, as Kind or Kind.group, printed with their built-in columns even though a printer definition exists for them (e.g. --builtin-printers Pod,Deployment.apps).")
+}
+
+// printerDefinition declares the columns of one kind.
+type printerDefinition struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Group   string                    `json:"group"`
+		Kind    string                    `json:"kind"`
+		Columns []printerDefinitionColumn `json:"columns"`
+	} `json:"spec"`
+}
+
+type printerDefinitionColumn struct {
+	Name        string `json:"name"`
+	JSONPath    string `json:"jsonPath"`
+	Type        string `json:"type"`
+	Format      string `json:"format"`
+	Description string `json:"description"`
+	Priority    int32  `json:"priority"`
+}
+
+var (
+	definitionColumnTypes   = sets.NewString("", "string", "integer", "number", "boolean", "date")
+	definitionColumnFormats = sets.NewString("", "name", "age", "quantity", "join", "map-keys")
+)
+
+// definedColumn is a column of a printer definition with its JSONPath
+// already parsed.
+type definedColumn struct {
+	definition metav1.TableColumnDefinition
+	format     string
+	parser     *jsonpath.JSONPath
+}
+
+// userPrinters holds a HumanReadableGenerator for every kind with a printer
+// definition. Generators register one handler per Go type, so every kind
+// gets a generator of its own.
+type userPrinters struct {
+	generators map[schema.GroupKind]*kprinters.HumanReadableGenerator
+	// resources are the resources of the kinds, for which the server is not
+	// asked to print Tables
+	resources sets.String
+}
+
+// loadUserPrinters reads the printer definitions of ~/.kget/printers and of
+// dirs, later definitions of a kind replacing earlier ones. The definitions of
+// builtin kinds are skipped. It returns nil when there are no definitions.
+func loadUserPrinters(dirs, builtin []string) (*userPrinters, error) {
+	definitions := map[schema.GroupKind][]definedColumn{}
+	if err := readPrinterDefinitions(filepath.Join(homedir.HomeDir(), ".kget", "printers"), definitions); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := readPrinterDefinitions(dir, definitions); err != nil {
+			return nil, err
+		}
+	}
+	for _, kind := range builtin {
+		builtinKind := schema.ParseGroupKind(kind)
+		for gk := range definitions {
+			if strings.EqualFold(gk.Kind, builtinKind.Kind) && (len(builtinKind.Group) == 0 || gk.Group == builtinKind.Group) {
+				delete(definitions, gk)
+			}
+		}
+	}
+	if len(definitions) == 0 {
+		return nil, nil
+	}
+
+	p := &userPrinters{
+		generators: map[schema.GroupKind]*kprinters.HumanReadableGenerator{},
+		resources:  sets.NewString(),
+	}
+	for gk, columns := range definitions {
+		p.generators[gk] = kprinters.NewTableGenerator().With(definedHandlers(columns))
+	}
+	return p, nil
+}
+
+// readPrinterDefinitions adds the definitions of the YAML and JSON files of
+// dir to definitions.
+func readPrinterDefinitions(dir string, definitions map[schema.GroupKind][]definedColumn) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		switch filepath.Ext(file.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		filename := filepath.Join(dir, file.Name())
+		if err := readPrinterDefinitionFile(filename, definitions); err != nil {
+			return fmt.Errorf("error reading printer definitions from %s: %v", filename, err)
+		}
+	}
+	return nil
+}
+
+func readPrinterDefinitionFile(filename string, definitions map[schema.GroupKind][]definedColumn) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		definition := &printerDefinition{}
+		if err := decoder.Decode(definition); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if len(definition.APIVersion) == 0 && len(definition.Kind) == 0 {
+			continue
+		}
+		if definition.APIVersion != printerDefinitionVersion || definition.Kind != "Printer" {
+			return fmt.Errorf("expected apiVersion %s and kind Printer, got %s %s", printerDefinitionVersion, definition.APIVersion, definition.Kind)
+		}
+		if len(definition.Spec.Kind) == 0 || len(definition.Spec.Columns) == 0 {
+			return fmt.Errorf("a printer must name a kind and declare columns")
+		}
+		columns, err := parseDefinedColumns(definition.Spec.Columns)
+		if err != nil {
+			return fmt.Errorf("printer of %s: %v", definition.Spec.Kind, err)
+		}
+		definitions[schema.GroupKind{Group: definition.Spec.Group, Kind: definition.Spec.Kind}] = columns
+	}
+}
+
+// parseDefinedColumns parses specs, adding a leading Name column unless they
+// declare one.
+func parseDefinedColumns(specs []printerDefinitionColumn) ([]definedColumn, error) {
+	hasName := false
+	for _, spec := range specs {
+		hasName = hasName || strings.EqualFold(spec.Name, "Name")
+	}
+	if !hasName {
+		specs = append([]printerDefinitionColumn{{Name: "Name", JSONPath: ".metadata.name", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]}}, specs...)
+	}
+
+	columns := make([]definedColumn, 0, len(specs))
+	for _, spec := range specs {
+		if len(spec.Name) == 0 {
+			return nil, fmt.Errorf("every column needs a name")
+		}
+		if !definitionColumnTypes.Has(spec.Type) {
+			return nil, fmt.Errorf("column %q has unknown type %q, one of %s", spec.Name, spec.Type, strings.Join(definitionColumnTypes.List()[1:], ", "))
+		}
+		if !definitionColumnFormats.Has(spec.Format) {
+			return nil, fmt.Errorf("column %q has unknown format %q, one of %s", spec.Name, spec.Format, strings.Join(definitionColumnFormats.List()[1:], ", "))
+		}
+		parser := jsonpath.New(spec.Name).AllowMissingKeys(true)
+		if err := parser.Parse(fmt.Sprintf("{%s}", spec.JSONPath)); err != nil {
+			return nil, fmt.Errorf("unrecognized column %q definition %q: %v", spec.Name, spec.JSONPath, err)
+		}
+		column := definedColumn{
+			definition: metav1.TableColumnDefinition{
+				Name:        spec.Name,
+				Type:        spec.Type,
+				Description: spec.Description,
+				Priority:    spec.Priority,
+			},
+			format: spec.Format,
+			parser: parser,
+		}
+		// ages are printed and compared as the ages of date columns
+		if spec.Format == "age" {
+			column.format, column.definition.Type = "", "date"
+		}
+		if len(column.definition.Type) == 0 {
+			column.definition.Type = "string"
+		}
+		if spec.Format == "name" {
+			column.definition.Format = "name"
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// definedHandlers registers the handlers printing unstructured objects with
+// columns.
+func definedHandlers(columns []definedColumn) func(kprinters.PrintHandler) {
+	definitions := make([]metav1.TableColumnDefinition, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, column.definition)
+	}
+	printRow := func(obj *unstructured.Unstructured, options kprinters.GenerateOptions) ([]metav1.TableRow, error) {
+		row := metav1.TableRow{
+			Object: runtime.RawExtension{Object: obj},
+		}
+		for _, column := range columns {
+			row.Cells = append(row.Cells, column.cell(obj.Object))
+		}
+		return []metav1.TableRow{row}, nil
+	}
+	printList := func(objList *unstructured.UnstructuredList, options kprinters.GenerateOptions) ([]metav1.TableRow, error) {
+		rows := make([]metav1.TableRow, 0, len(objList.Items))
+		for i := range objList.Items {
+			r, err := printRow(&objList.Items[i], options)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, r...)
+		}
+		return rows, nil
+	}
+	return func(h kprinters.PrintHandler) {
+		h.TableHandler(definitions, printList)
+		h.TableHandler(definitions, printRow)
+	}
+}
+
+// cell evaluates the column against obj and formats the values found.
+func (c definedColumn) cell(obj map[string]interface{}) interface{} {
+	results, err := c.parser.FindResults(obj)
+	if err != nil {
+		klog.V(4).Infof("Unable to evaluate column %q: %v", c.definition.Name, err)
+		return "<none>"
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, value.Interface())
+		}
+	}
+	if len(values) == 0 || len(values) == 1 && values[0] == nil {
+		return "<none>"
+	}
+
+	switch c.format {
+	case "join":
+		if len(values) == 1 {
+			if list, ok := values[0].([]interface{}); ok {
+				values = list
+			}
+		}
+		return joinValues(values)
+	case "map-keys":
+		m, ok := values[0].(map[string]interface{})
+		if !ok {
+			return fmt.Sprint(values[0])
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ",")
+	case "quantity":
+		q, err := resource.ParseQuantity(fmt.Sprint(values[0]))
+		if err != nil {
+			return fmt.Sprint(values[0])
+		}
+		return q.String()
+	}
+	if len(values) > 1 {
+		return joinValues(values)
+	}
+
+	value := values[0]
+	switch c.definition.Type {
+	case "date":
+		s, ok := value.(string)
+		if !ok {
+			return value
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return s
+		}
+		return translateTimestampSince(metav1.NewTime(t))
+	case "integer":
+		// objects decoded from JSON carry numbers as float64
+		if f, ok := value.(float64); ok {
+			return int64(f)
+		}
+	case "string":
+		return fmt.Sprint(value)
+	}
+	return value
+}
+
+func joinValues(values []interface{}) string {
+	s := make([]string, 0, len(values))
+	for _, value := range values {
+		s = append(s, fmt.Sprint(value))
+	}
+	return strings.Join(s, ",")
+}
+
+// generatorFor returns the generator of the kind of obj, which may be a
+// list, or nil when the kind has no printer definition.
+func (p *userPrinters) generatorFor(obj runtime.Object) *kprinters.HumanReadableGenerator {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if _, ok := obj.(*unstructured.UnstructuredList); ok {
+		gk.Kind = strings.TrimSuffix(gk.Kind, "List")
+	}
+	return p.generators[gk]
+}
+
+// ToTable prints obj with the printer definition of its kind. It reports
+// false if its kind has none.
+func (p *userPrinters) ToTable(obj runtime.Object) (*metav1.Table, bool, error) {
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList:
+	default:
+		return nil, false, nil
+	}
+	generator := p.generatorFor(obj)
+	if generator == nil {
+		return nil, false, nil
+	}
+	table, err := generator.GenerateTable(obj, kprinters.GenerateOptions{Wide: true})
+	return table, true, err
+}
+
+// resolveResources finds the resources of the kinds with a definition, so
+// that transformRequests leaves them to client-side printing. Kinds the
+// cluster does not serve are skipped.
+func (p *userPrinters) resolveResources(f cmdutil.Factory) error {
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	for gk := range p.generators {
+		mapping, err := mapper.RESTMapping(gk)
+		if err != nil {
+			klog.V(2).Infof("Unable to find the resource of printer definition %v: %v", gk, err)
+			continue
+		}
+		p.resources.Insert(mapping.Resource.GroupResource().String())
+	}
+	return nil
+}
+
+// forContext returns the printers with the resources of the cluster of f.
+func (p *userPrinters) forContext(f cmdutil.Factory) (*userPrinters, error) {
+	c := &userPrinters{generators: p.generators, resources: sets.NewString()}
+	return c, c.resolveResources(f)
+}
+
+// printsRequest reports whether the resource requested at path, an API path
+// like /apis/apps/v1/namespaces/default/deployments, has a definition.
+func (p *userPrinters) printsRequest(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var group string
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group, parts = parts[1], parts[3:]
+	default:
+		return false
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	return p.resources.Has(schema.GroupResource{Group: group, Resource: parts[0]}.String())
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseDefinedColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		specs       []printerDefinitionColumn
+		definitions []metav1.TableColumnDefinition
+		err         string
+	}{
+		{
+			name:  "leading name column",
+			specs: []printerDefinitionColumn{{Name: "Ready", JSONPath: ".status.readyReplicas", Type: "integer"}},
+			definitions: []metav1.TableColumnDefinition{
+				{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+				{Name: "Ready", Type: "integer"},
+			},
+		},
+		{
+			name: "declared name column",
+			specs: []printerDefinitionColumn{
+				{Name: "Ready", JSONPath: ".status.readyReplicas", Type: "integer", Priority: 1},
+				{Name: "NAME", JSONPath: ".metadata.name"},
+				{Name: "Age", JSONPath: ".metadata.creationTimestamp", Format: "age"},
+			},
+			definitions: []metav1.TableColumnDefinition{
+				{Name: "Ready", Type: "integer", Priority: 1},
+				{Name: "NAME", Type: "string"},
+				{Name: "Age", Type: "date"},
+			},
+		},
+		{
+			name:  "unnamed column",
+			specs: []printerDefinitionColumn{{JSONPath: ".spec"}},
+			err:   "every column needs a name",
+		},
+		{
+			name:  "unknown type",
+			specs: []printerDefinitionColumn{{Name: "Ready", JSONPath: ".status.ready", Type: "int"}},
+			err:   `column "Ready" has unknown type "int"`,
+		},
+		{
+			name:  "unknown format",
+			specs: []printerDefinitionColumn{{Name: "Hosts", JSONPath: ".spec.rules[*].host", Format: "csv"}},
+			err:   `column "Hosts" has unknown format "csv"`,
+		},
+		{
+			name:  "invalid JSONPath",
+			specs: []printerDefinitionColumn{{Name: "Hosts", JSONPath: ".spec.rules[*"}},
+			err:   `unrecognized column "Hosts" definition`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns, err := parseDefinedColumns(test.specs)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			definitions := make([]metav1.TableColumnDefinition, 0, len(columns))
+			for _, column := range columns {
+				definitions = append(definitions, column.definition)
+			}
+			if !reflect.DeepEqual(definitions, test.definitions) {
+				t.Errorf("expected %v, got %v", test.definitions, definitions)
+			}
+		})
+	}
+}
+
+func TestDefinedColumnCell(t *testing.T) {
+	created := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":              "web",
+			"creationTimestamp": created.Format(time.RFC3339),
+			"labels":            map[string]interface{}{"tier": "front", "app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(3),
+			"hosts":    []interface{}{"a.example.com", "b.example.com"},
+			"rules": []interface{}{
+				map[string]interface{}{"host": "a.example.com"},
+				map[string]interface{}{"host": "b.example.com"},
+			},
+			"memory":  "1024Mi",
+			"enabled": true,
+		},
+	}
+	tests := []struct {
+		name     string
+		spec     printerDefinitionColumn
+		expected interface{}
+	}{
+		{name: "string", spec: printerDefinitionColumn{Name: "Name", JSONPath: ".metadata.name"}, expected: "web"},
+		{name: "integer", spec: printerDefinitionColumn{Name: "Replicas", JSONPath: ".spec.replicas", Type: "integer"}, expected: int64(3)},
+		{name: "number", spec: printerDefinitionColumn{Name: "Replicas", JSONPath: ".spec.replicas", Type: "number"}, expected: float64(3)},
+		{name: "boolean", spec: printerDefinitionColumn{Name: "Enabled", JSONPath: ".spec.enabled", Type: "boolean"}, expected: true},
+		{name: "missing", spec: printerDefinitionColumn{Name: "Ready", JSONPath: ".status.ready"}, expected: "<none>"},
+		{name: "several values", spec: printerDefinitionColumn{Name: "Hosts", JSONPath: ".spec.rules[*].host"}, expected: "a.example.com,b.example.com"},
+		{name: "join", spec: printerDefinitionColumn{Name: "Hosts", JSONPath: ".spec.hosts", Format: "join"}, expected: "a.example.com,b.example.com"},
+		{name: "map-keys", spec: printerDefinitionColumn{Name: "Labels", JSONPath: ".metadata.labels", Format: "map-keys"}, expected: "app,tier"},
+		{name: "quantity", spec: printerDefinitionColumn{Name: "Memory", JSONPath: ".spec.memory", Format: "quantity"}, expected: "1Gi"},
+		{name: "age", spec: printerDefinitionColumn{Name: "Age", JSONPath: ".metadata.creationTimestamp", Format: "age"}, expected: "2020-01-01"},
+	}
+	defer func(format string) { timestampFormat = format }(timestampFormat)
+	timestampFormat = "2006-01-02"
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns, err := parseDefinedColumns([]printerDefinitionColumn{{Name: "Name", JSONPath: ".metadata.name"}, test.spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cell := columns[len(columns)-1].cell(obj); !reflect.DeepEqual(cell, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, cell)
+			}
+		})
+	}
+}
+
+func TestReadPrinterDefinitionFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[schema.GroupKind][]string
+		err      string
+	}{
+		{
+			name: "several documents",
+			content: `apiVersion: kget.io/v1
+kind: Printer
+spec:
+  group: example.com
+  kind: Widget
+  columns:
+  - name: Ready
+    jsonPath: .status.readyReplicas
+---
+---
+apiVersion: kget.io/v1
+kind: Printer
+spec:
+  kind: ConfigMap
+  columns:
+  - name: Name
+    jsonPath: .metadata.name
+  - name: Keys
+    jsonPath: .data
+    format: map-keys
+`,
+			expected: map[schema.GroupKind][]string{
+				{Group: "example.com", Kind: "Widget"}: {"Name", "Ready"},
+				{Kind: "ConfigMap"}:                    {"Name", "Keys"},
+			},
+		},
+		{
+			name:    "wrong kind",
+			content: "apiVersion: v1\nkind: ConfigMap\n",
+			err:     "expected apiVersion kget.io/v1 and kind Printer",
+		},
+		{
+			name:    "no columns",
+			content: "apiVersion: kget.io/v1\nkind: Printer\nspec:\n  kind: Widget\n",
+			err:     "a printer must name a kind and declare columns",
+		},
+		{
+			name:    "invalid column",
+			content: "apiVersion: kget.io/v1\nkind: Printer\nspec:\n  kind: Widget\n  columns:\n  - name: Ready\n    jsonPath: .status\n    type: int\n",
+			err:     "printer of Widget: column \"Ready\" has unknown type",
+		},
+	}
+	dir, err := ioutil.TempDir("", "printers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(dir, strings.Repeat("x", i+1)+".yaml")
+			if err := ioutil.WriteFile(filename, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			definitions := map[schema.GroupKind][]definedColumn{}
+			err := readPrinterDefinitionFile(filename, definitions)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := map[schema.GroupKind][]string{}
+			for gk, columns := range definitions {
+				for _, column := range columns {
+					names[gk] = append(names[gk], column.definition.Name)
+				}
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
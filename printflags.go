@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/cmd/get"
+)
+
+// PrintFlags composes the printer flags of kubectl get with the formats kget
+// renders from the generated Tables.
+type PrintFlags struct {
+	*get.PrintFlags
+
+	TabularFlags *TabularPrintFlags
+	RowsFlags    *RowsPrintFlags
+	TableFlags   *TablePrintFlags
+
+	// EscapedCells is set when the cells of the printed Tables are escaped
+	// by colorTable, for the label columns to be escaped the same way.
+	EscapedCells bool
+}
+
+// NewPrintFlags returns flags associated with every output format of kget,
+// with default values set.
+func NewPrintFlags() *PrintFlags {
+	return &PrintFlags{
+		PrintFlags:   get.NewGetPrintFlags(),
+		TabularFlags: NewTabularPrintFlags(),
+		RowsFlags:    NewRowsPrintFlags(),
+		TableFlags:   NewTablePrintFlags(),
+	}
+}
+
+// AllowedFormats is the list of formats in which data can be displayed
+func (f *PrintFlags) AllowedFormats() []string {
+	formats := f.PrintFlags.AllowedFormats()
+	formats = append(formats, f.TabularFlags.AllowedFormats()...)
+	formats = append(formats, f.RowsFlags.AllowedFormats()...)
+	formats = append(formats, f.TableFlags.AllowedFormats()...)
+	return formats
+}
+
+// ToPrinter attempts to find a composed set of PrintFlags suitable for
+// returning a printer based on current flag values.
+func (f *PrintFlags) ToPrinter() (printers.ResourcePrinter, error) {
+	if f.NoHeaders != nil {
+		f.TabularFlags.NoHeaders = *f.NoHeaders
+	}
+	if p, err := f.TabularFlags.ToPrinter(f.outputFormat()); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+	f.RowsFlags.Kind = f.HumanReadableFlags.Kind
+	if p, err := f.RowsFlags.ToPrinter(f.outputFormat()); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+	if p, err := f.TableFlags.ToPrinter(f.outputFormat()); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+	if !f.EscapedCells || !f.IsHumanReadable() {
+		return f.PrintFlags.ToPrinter()
+	}
+	// the label columns are added before the printer sees the Table
+	labels := &labelColumnsPrinter{columnLabels: *f.HumanReadableFlags.ColumnLabels, showLabels: *f.HumanReadableFlags.ShowLabels}
+	*f.HumanReadableFlags.ColumnLabels, *f.HumanReadableFlags.ShowLabels = nil, false
+	p, err := f.PrintFlags.ToPrinter()
+	*f.HumanReadableFlags.ColumnLabels, *f.HumanReadableFlags.ShowLabels = labels.columnLabels, labels.showLabels
+	if err != nil {
+		return nil, err
+	}
+	labels.delegate = p
+	return labels, nil
+}
+
+// AddFlags receives a *cobra.Command reference and binds flags related to
+// every output format to it.
+func (f *PrintFlags) AddFlags(cmd *cobra.Command) {
+	f.PrintFlags.AddFlags(cmd)
+	f.TableFlags.AddFlags(cmd)
+	if output := cmd.Flags().Lookup("output"); output != nil {
+		output.Usage = "Output format. One of: json|yaml|wide|name|markdown|csv|tsv|html|markdown-wide|csv-wide|tsv-wide|html-wide|jsonl-rows|table-json|table-yaml|custom-columns=...|custom-columns-file=...|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=... See custom columns [http://kubernetes.io/docs/user-guide/kubectl-overview/#custom-columns], golang template [http://golang.org/pkg/text/template/#pkg-overview] and jsonpath template [http://kubernetes.io/docs/user-guide/jsonpath]."
+	}
+}
+
+// PrintsTables reports whether the output format renders the generated
+// Tables rather than the objects themselves.
+func (f *PrintFlags) PrintsTables() bool {
+	return f.IsHumanReadable() || f.TabularFlags.Allows(f.outputFormat()) || f.StreamsRows() || f.TableFlags.Allows(f.outputFormat())
+}
+
+// StreamsRows reports whether the output format writes every row of the
+// generated Tables on a line of its own.
+func (f *PrintFlags) StreamsRows() bool {
+	return f.RowsFlags.Allows(f.outputFormat())
+}
+
+// IsHumanReadable reports whether the output format is the aligned table
+// printed through a tabwriter.
+func (f *PrintFlags) IsHumanReadable() bool {
+	outputFormat := f.outputFormat()
+	return outputFormat == "" || outputFormat == "wide"
+}
+
+func (f *PrintFlags) outputFormat() string {
+	if f.OutputFormat == nil {
+		return ""
+	}
+	return *f.OutputFormat
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+// Escape sequences switching to the alternate screen without cursor and line
+// wrapping, and back.
+const (
+	enterDashboard = "\x1b[?1049h\x1b[?25l\x1b[?7l"
+	leaveDashboard = "\x1b[?7h\x1b[?25h\x1b[?1049l"
+)
+
+const dashboardHelp = "1-9 sort by column, again to reverse  0 sort by name  / filter  n namespace  q quit"
+
+// addRefreshFlag binds --refresh to o.Refresh.
+func addRefreshFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().DurationVar(&o.Refresh, "refresh", o.Refresh, "If set, show the requested resource full screen, redrawn at this interval (e.g. --refresh 2s) from a watch cache. Rows added, changed or deleted since the previous frame are highlighted. Press 1-9 to sort by a column, / to filter with a --where expression, n to switch namespace and q to quit.")
+}
+
+// dashboard shows the objects of one resource full screen, kept up to date by
+// an informer, redrawing them as a table every o.Refresh.
+type dashboard struct {
+	o         *GetOptions
+	mapping   *meta.RESTMapping
+	resource  dynamic.NamespaceableResourceInterface
+	names     sets.String
+	converter *tableConverter
+	wide      bool
+
+	namespace string
+	store     cache.Store
+	synced    chan struct{}
+	stop      chan struct{}
+
+	// sortColumn is the 1-based index of the visible column rows are sorted
+	// by, 0 sorts them by namespace and name
+	sortColumn int
+	reverse    bool
+	filter     whereExpr
+	filterText string
+
+	// prompt is the question the footer asks, answered by input
+	prompt  string
+	input   string
+	message string
+
+	columns  []metav1.TableColumnDefinition
+	previous map[types.UID]*dashboardRow
+}
+
+// dashboardRow is a row of a frame, with what happened to its object since
+// the previous frame: one of colorAdded, colorChanged, colorDeleted or "".
+type dashboardRow struct {
+	uid             types.UID
+	resourceVersion string
+	namespace       string
+	name            string
+	row             metav1.TableRow
+	change          string
+}
+
+// runDashboard shows the requested resource until the user quits.
+func (o *GetOptions) runDashboard(f cmdutil.Factory, args []string) error {
+	r := o.watchResult(f, args)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	infos, err := r.Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		return fmt.Errorf("no resources found to show")
+	}
+	if multipleGVKsRequested(infos) {
+		return fmt.Errorf("--refresh is only supported on individual resources and resource collections - more than 1 resource was found")
+	}
+
+	mapping := infos[0].ResourceMapping()
+	o.PrintFlags.SetKind(mapping.GroupVersionKind.GroupKind())
+	client, err := f.DynamicClient()
+	if err != nil {
+		return err
+	}
+	d := &dashboard{
+		o:         o,
+		mapping:   mapping,
+		resource:  client.Resource(mapping.Resource),
+		names:     sets.NewString(),
+		converter: o.newTableConverter(),
+		wide:      o.PrintFlags.outputFormat() == "wide",
+	}
+	// objects requested by name are watched among all of their resource
+	for _, info := range infos {
+		if len(info.Name) > 0 {
+			d.names.Insert(info.Name)
+		}
+	}
+	if d.namespaced() && !o.AllNamespaces {
+		d.namespace = o.Namespace
+	}
+
+	leave := interrupt.New(nil, func() {
+		io.WriteString(o.Out, leaveDashboard)
+	})
+	tty := term.TTY{In: o.In, Out: o.Out, Raw: true, Parent: leave}
+	return tty.Safe(func() error {
+		io.WriteString(o.Out, enterDashboard)
+		return d.run()
+	})
+}
+
+func (d *dashboard) namespaced() bool {
+	return d.mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+// start runs an informer filling d.store with the objects of d.namespace.
+func (d *dashboard) start() {
+	namespace := d.namespace
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector, options.FieldSelector = d.o.LabelSelector, d.o.FieldSelector
+			return d.resource.Namespace(namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector, options.FieldSelector = d.o.LabelSelector, d.o.FieldSelector
+			return d.resource.Namespace(namespace).Watch(context.TODO(), options)
+		},
+	}
+	store, controller := cache.NewInformer(lw, &unstructured.Unstructured{}, 0, cache.ResourceEventHandlerFuncs{})
+	stop, synced := make(chan struct{}), make(chan struct{})
+	go controller.Run(stop)
+	go func() {
+		if cache.WaitForCacheSync(stop, controller.HasSynced) {
+			close(synced)
+		}
+	}()
+	d.store, d.stop, d.synced = store, stop, synced
+	d.previous = nil
+}
+
+// run draws frames until the user quits or the input ends.
+func (d *dashboard) run() error {
+	keys := make(chan byte, 64)
+	go readKeys(d.o.In, keys)
+	ticker := time.NewTicker(d.o.Refresh)
+	defer ticker.Stop()
+
+	d.start()
+	defer func() { close(d.stop) }()
+	for {
+		if err := d.draw(); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-d.synced:
+			// draw the first frame as soon as the objects are known
+			d.synced = nil
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil
+			}
+		}
+	}
+}
+
+// readKeys sends the bytes read from in to keys, closing it at the end of
+// the input.
+func readKeys(in io.Reader, keys chan<- byte) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, b := range buf[:n] {
+			keys <- b
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleKey applies a key press and reports whether the user quit.
+func (d *dashboard) handleKey(key byte) bool {
+	const ctrlC, backspace, del, escape = 3, 8, 127, 27
+	if key == ctrlC {
+		return true
+	}
+	if len(d.prompt) > 0 {
+		switch key {
+		case '\r', '\n':
+			d.answer(strings.TrimSpace(d.input))
+			d.prompt, d.input = "", ""
+		case escape:
+			d.prompt, d.input = "", ""
+		case backspace, del:
+			if len(d.input) > 0 {
+				d.input = d.input[:len(d.input)-1]
+			}
+		default:
+			if key >= ' ' && key < del {
+				d.input += string(key)
+			}
+		}
+		return false
+	}
+
+	d.message = ""
+	switch {
+	case key == 'q':
+		return true
+	case key == '0':
+		d.sortColumn, d.reverse = 0, false
+	case key >= '1' && key <= '9':
+		column := int(key - '0')
+		d.reverse = column == d.sortColumn && !d.reverse
+		d.sortColumn = column
+	case key == '/':
+		d.prompt, d.input = "Filter (a --where expression, empty to clear): ", d.filterText
+	case key == 'n':
+		if !d.namespaced() {
+			d.message = fmt.Sprintf("%s are not namespaced", d.mapping.Resource.Resource)
+			break
+		}
+		d.prompt, d.input = "Namespace (empty for all namespaces): ", d.namespace
+	}
+	return false
+}
+
+// answer applies the input of the current prompt.
+func (d *dashboard) answer(input string) {
+	if strings.HasPrefix(d.prompt, "Namespace") {
+		if input == d.namespace {
+			return
+		}
+		close(d.stop)
+		d.namespace = input
+		d.start()
+		return
+	}
+
+	if len(input) == 0 {
+		d.filter, d.filterText = nil, ""
+		d.previous = nil
+		return
+	}
+	filter, err := parseWhere(input)
+	if err != nil {
+		d.message = err.Error()
+		return
+	}
+	d.filter, d.filterText = filter, input
+	d.previous = nil
+}
+
+// draw writes a frame: a title, the table and the footer on the last line.
+func (d *dashboard) draw() error {
+	height := 24
+	if size := (term.TTY{Out: d.o.Out}).GetSize(); size != nil && size.Height > 0 {
+		height = int(size.Height)
+	}
+
+	var body bytes.Buffer
+	shown, hidden := 0, 0
+	switch {
+	case d.synced != nil:
+		fmt.Fprintf(&body, "Waiting for %s...\n", d.mapping.Resource.Resource)
+	default:
+		table, err := d.table()
+		if err != nil && d.filter != nil {
+			// an interactive filter naming an unknown column is dropped
+			d.message = err.Error()
+			d.filter, d.filterText = nil, ""
+			table, err = d.table()
+		}
+		if err != nil {
+			return err
+		}
+		// leave room for the title, the headers and the footer
+		if max := height - 4; len(table.Rows) > max && max > 0 {
+			shown, hidden = max, len(table.Rows)-max
+			table.Rows = table.Rows[:max]
+		} else {
+			shown = len(table.Rows)
+		}
+		if len(table.Rows) == 0 {
+			fmt.Fprintf(&body, "No resources found%s.\n", d.location())
+			break
+		}
+		// a new printer writes the headers again
+		printer, err := d.o.PrintFlags.ToPrinter()
+		if err != nil {
+			return err
+		}
+		w := d.o.newTableWriter(&body)
+		if err := printer.PrintObj(table, w); err != nil {
+			return err
+		}
+		w.Flush()
+	}
+
+	title := fmt.Sprintf("Every %s: %s%s", d.o.Refresh, d.mapping.Resource.Resource, d.location())
+	if hidden > 0 {
+		title += fmt.Sprintf(", %d of %d shown", shown, shown+hidden)
+	}
+	if name := d.sortName(); len(name) > 0 {
+		order := "ascending"
+		if d.reverse {
+			order = "descending"
+		}
+		title += fmt.Sprintf("  sorted by %s %s", name, order)
+	}
+	if d.filter != nil {
+		title += "  filter: " + d.filterText
+	}
+	title += "  " + time.Now().Format("15:04:05")
+
+	footer := dashboardHelp
+	switch {
+	case len(d.prompt) > 0:
+		footer = d.prompt + d.input
+	case len(d.message) > 0:
+		footer = d.message
+	}
+
+	// overwrite the previous frame in place, the terminal is in raw mode
+	var frame bytes.Buffer
+	frame.WriteString("\x1b[H")
+	for _, line := range append([]string{title, ""}, strings.Split(strings.TrimSuffix(body.String(), "\n"), "\n")...) {
+		frame.WriteString(line + "\x1b[K\r\n")
+	}
+	fmt.Fprintf(&frame, "\x1b[J\x1b[%d;1H%s\x1b[K", height, footer)
+	_, err := d.o.Out.Write(frame.Bytes())
+	return err
+}
+
+// location describes the namespace shown.
+func (d *dashboard) location() string {
+	switch {
+	case !d.namespaced():
+		return ""
+	case len(d.namespace) == 0:
+		return " in all namespaces"
+	}
+	return fmt.Sprintf(" in %s namespace", d.namespace)
+}
+
+// table returns the rows of the current frame, sorted and highlighted.
+func (d *dashboard) table() (*metav1.Table, error) {
+	current := map[types.UID]*dashboardRow{}
+	var rows []*dashboardRow
+	for _, item := range d.store.List() {
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok || d.names.Len() > 0 && !d.names.Has(obj.GetName()) {
+			continue
+		}
+		table, err := d.o.toFilteredTable(d.converter, obj)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.o.checkColumns(); err != nil {
+			return nil, err
+		}
+		if d.filter != nil {
+			if err := d.o.printedColumns.Check("--where", d.filter.columns()...); err != nil {
+				return nil, err
+			}
+			if err := filterRows(d.filter, table); err != nil {
+				return nil, err
+			}
+		}
+		d.columns = table.ColumnDefinitions
+		for _, row := range table.Rows {
+			r := &dashboardRow{
+				uid:             obj.GetUID(),
+				resourceVersion: obj.GetResourceVersion(),
+				namespace:       obj.GetNamespace(),
+				name:            obj.GetName(),
+				row:             row,
+			}
+			if d.previous != nil {
+				if prev, ok := d.previous[r.uid]; !ok {
+					r.change = colorAdded
+				} else if prev.resourceVersion != r.resourceVersion {
+					r.change = colorChanged
+				}
+			}
+			current[r.uid] = r
+			rows = append(rows, r)
+		}
+	}
+	// deleted rows are shown once more
+	for uid, prev := range d.previous {
+		if _, ok := current[uid]; !ok {
+			deleted := *prev
+			deleted.change = colorDeleted
+			rows = append(rows, &deleted)
+		}
+	}
+	d.previous = current
+
+	table := &metav1.Table{ColumnDefinitions: d.columns}
+	if len(rows) == 0 {
+		return table, nil
+	}
+	for _, r := range rows {
+		// the cells of rows are kept for the next frame, colorTable changes them
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: append([]interface{}(nil), r.row.Cells...), Object: r.row.Object})
+	}
+	if err := d.o.columns.Apply(table, d.mapping); err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	column := d.sortIndex(table)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if column != -1 {
+			if c := compareCells(cellAt(table.Rows[a].Cells, column), cellAt(table.Rows[b].Cells, column)); c != 0 {
+				return (c < 0) != d.reverse
+			}
+		}
+		if rows[a].namespace != rows[b].namespace {
+			return rows[a].namespace < rows[b].namespace
+		}
+		return rows[a].name < rows[b].name
+	})
+	sorted := make([]metav1.TableRow, 0, len(order))
+	for _, i := range order {
+		sorted = append(sorted, table.Rows[i])
+	}
+	table.Rows = sorted
+	d.o.colorTheme.colorTable(table)
+	for i, j := range order {
+		d.o.colorTheme.highlightRow(&table.Rows[i], rows[j].change)
+	}
+	return table, nil
+}
+
+// visibleColumns returns the indexes of the columns of table the printer
+// shows.
+func (d *dashboard) visibleColumns(columns []metav1.TableColumnDefinition) []int {
+	var visible []int
+	for i, column := range columns {
+		if column.Priority == 0 || d.wide {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// sortIndex returns the index of the column of table rows are sorted by, or
+// -1 when they are sorted by name.
+func (d *dashboard) sortIndex(table *metav1.Table) int {
+	visible := d.visibleColumns(table.ColumnDefinitions)
+	if d.sortColumn == 0 || d.sortColumn > len(visible) {
+		return -1
+	}
+	return visible[d.sortColumn-1]
+}
+
+// sortName returns the header of the column rows are sorted by, if any.
+func (d *dashboard) sortName() string {
+	table := &metav1.Table{ColumnDefinitions: d.columns}
+	if err := d.o.columns.Apply(table, d.mapping); err != nil {
+		return ""
+	}
+	if i := d.sortIndex(table); i != -1 {
+		return strings.ToUpper(table.ColumnDefinitions[i].Name)
+	}
+	return ""
+}
+
+// compareCells orders two cells as numbers or ages when both are, like
+// --where compares them, and as strings otherwise.
+func compareCells(a, b interface{}) int {
+	s, t := fmt.Sprint(a), fmt.Sprint(b)
+	if x, ok := leadingNumber(s); ok {
+		if y, ok := leadingNumber(t); ok {
+			return compareFloats(x, y)
+		}
+	}
+	if x, err := parseAge(s); err == nil {
+		if y, err := parseAge(t); err == nil {
+			return compareFloats(float64(x), float64(y))
+		}
+	}
+	return strings.Compare(s, t)
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
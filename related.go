@@ -0,0 +1,483 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewRelatedCommand returns a command printing the objects a Service, Pod or
+// workload works with.
+func NewRelatedCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
+	cmd := &cobra.Command{
+		Use:   "related (TYPE[.VERSION][.GROUP] NAME | TYPE[.VERSION][.GROUP]/NAME)",
+		Short: "related demo",
+		Long:  "Print the objects related to a Service (its Endpoints, the Pods it selects and the Ingresses routing to it), a Pod (its owners, the ConfigMaps, Secrets and PersistentVolumeClaims it uses, its ServiceAccount, its Node and the Services selecting it) or a workload (its owners, Pods, ConfigMaps, Secrets, PersistentVolumeClaims, ServiceAccount and the Services selecting its Pods), one table per section.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("an object is required, e.g. svc/api")
+			}
+			if err := o.Complete(f, cmd, args); err != nil {
+				return err
+			}
+			return o.runRelated(f, args)
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	addColorFlag(cmd, o)
+	addPrintersFlags(cmd, o)
+
+	return cmd
+}
+
+// relatedSection is a group of related objects, printed under its title.
+// Objects that are referenced but could not be read are described in missing.
+type relatedSection struct {
+	title   string
+	infos   []*resource.Info
+	missing []string
+}
+
+// relatedLookup reads the objects related to another one.
+type relatedLookup struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+// runRelated prints the sections of the object named by args.
+func (o *GetOptions) runRelated(f cmdutil.Factory, args []string) error {
+	obj, err := o.singleObject(f, args)
+	if err != nil {
+		return err
+	}
+	client, err := f.DynamicClient()
+	if err != nil {
+		return err
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	l := &relatedLookup{client: client, mapper: mapper}
+
+	var sections []*relatedSection
+	gk := obj.GroupVersionKind().GroupKind()
+	switch {
+	case gk == schema.GroupKind{Kind: "Service"}:
+		sections, err = l.serviceSections(obj)
+	case gk == schema.GroupKind{Kind: "Pod"}:
+		sections, err = l.podSections(obj)
+	case len(podTemplatePath(gk)) > 0:
+		sections, err = l.workloadSections(obj)
+	default:
+		return fmt.Errorf("related supports Services, Pods and workloads, not %s", gk)
+	}
+	if err != nil {
+		return err
+	}
+	return o.printRelated(sections)
+}
+
+// printRelated prints every section as its own titled table, describing the
+// missing objects below it. Output that is not for humans holds the objects of
+// all the sections, in a single List when they are printed as is, and the
+// missing objects are warned about.
+func (o *GetOptions) printRelated(sections []*relatedSection) error {
+	if !o.PrintFlags.IsHumanReadable() {
+		var infos []*resource.Info
+		for _, s := range sections {
+			infos = append(infos, s.infos...)
+			for _, missing := range s.missing {
+				fmt.Fprintf(o.ErrOut, "warning: %s\n", missing)
+			}
+		}
+		if o.PrintFlags.PrintsTables() {
+			return o.printInfos(groupByResource(infos), nil)
+		}
+		return o.printList(infos)
+	}
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		fmt.Fprintf(o.Out, "%s:\n", s.title)
+		if len(s.infos) == 0 && len(s.missing) == 0 {
+			fmt.Fprintln(o.Out, "<none>")
+		}
+		if len(s.infos) > 0 {
+			if err := o.printInfos(groupByResource(s.infos), nil); err != nil {
+				return err
+			}
+		}
+		for _, missing := range s.missing {
+			fmt.Fprintln(o.Out, missing)
+		}
+	}
+	return nil
+}
+
+// printList prints the objects of infos as is, in one v1 List.
+func (o *GetOptions) printList(infos []*resource.Info) error {
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	list.SetAPIVersion("v1")
+	list.SetKind("List")
+	for _, info := range infos {
+		list.Items = append(list.Items, *info.Object.(*unstructured.Unstructured))
+	}
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(list, o.Out)
+}
+
+// serviceSections returns the Endpoints of svc, the Pods it selects and the
+// Ingresses routing to it.
+func (l *relatedLookup) serviceSections(svc *unstructured.Unstructured) ([]*relatedSection, error) {
+	endpoints := &relatedSection{title: "Endpoints"}
+	if err := l.add(endpoints, schema.GroupKind{Kind: "Endpoints"}, svc.GetNamespace(), svc.GetName()); err != nil {
+		return nil, err
+	}
+
+	pods := &relatedSection{title: "Pods selected by the Service"}
+	selector, _, _ := unstructured.NestedStringMap(svc.Object, "spec", "selector")
+	if len(selector) > 0 {
+		pods.title = fmt.Sprintf("Pods selected by %s", labels.SelectorFromSet(selector))
+		infos, err := l.list(pods, schema.GroupKind{Kind: "Pod"}, svc.GetNamespace(), labels.SelectorFromSet(selector).String())
+		if err != nil {
+			return nil, err
+		}
+		pods.infos = infos
+	} else {
+		pods.missing = append(pods.missing, "the Service has no selector, its Endpoints are managed by hand")
+	}
+
+	ingresses := &relatedSection{title: "Ingresses routing to the Service"}
+	for _, gk := range []schema.GroupKind{{Group: "networking.k8s.io", Kind: "Ingress"}, {Group: "extensions", Kind: "Ingress"}} {
+		if _, err := l.mapper.RESTMapping(gk); err != nil {
+			continue
+		}
+		infos, err := l.list(ingresses, gk, svc.GetNamespace(), "")
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if ingressBackends(info.Object.(*unstructured.Unstructured)).Has(svc.GetName()) {
+				ingresses.infos = append(ingresses.infos, info)
+			}
+		}
+		break
+	}
+	return []*relatedSection{endpoints, pods, ingresses}, nil
+}
+
+// ingressBackends returns the names of the Services an Ingress routes to,
+// for both the networking.k8s.io/v1 and the older schema.
+func ingressBackends(ingress *unstructured.Unstructured) sets.String {
+	names := sets.NewString()
+	addBackend := func(backend map[string]interface{}) {
+		if name, _, _ := unstructured.NestedString(backend, "service", "name"); len(name) > 0 {
+			names.Insert(name)
+		}
+		if name, _, _ := unstructured.NestedString(backend, "serviceName"); len(name) > 0 {
+			names.Insert(name)
+		}
+	}
+	for _, field := range []string{"defaultBackend", "backend"} {
+		if backend, ok, _ := unstructured.NestedMap(ingress.Object, "spec", field); ok {
+			addBackend(backend)
+		}
+	}
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	for _, rule := range rules {
+		r, _ := rule.(map[string]interface{})
+		paths, _, _ := unstructured.NestedSlice(r, "http", "paths")
+		for _, path := range paths {
+			p, _ := path.(map[string]interface{})
+			if backend, ok, _ := unstructured.NestedMap(p, "backend"); ok {
+				addBackend(backend)
+			}
+		}
+	}
+	return names
+}
+
+// podSections returns the owners of pod, the objects its spec refers to and
+// the Services selecting it.
+func (l *relatedLookup) podSections(pod *unstructured.Unstructured) ([]*relatedSection, error) {
+	owners, err := l.ownerSection(pod)
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(pod.Object, "spec")
+	sections, err := l.podSpecSections(pod.GetNamespace(), spec)
+	if err != nil {
+		return nil, err
+	}
+	node := &relatedSection{title: "Node"}
+	if name, _, _ := unstructured.NestedString(spec, "nodeName"); len(name) > 0 {
+		if err := l.add(node, schema.GroupKind{Kind: "Node"}, "", name); err != nil {
+			return nil, err
+		}
+	} else {
+		node.missing = append(node.missing, "the Pod is not scheduled")
+	}
+	services, err := l.selectingServices(pod.GetNamespace(), pod.GetLabels())
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]*relatedSection{owners}, sections...), node, services), nil
+}
+
+// workloadSections returns the owners of a workload, its Pods, the objects
+// its Pod template refers to and the Services selecting its Pods.
+func (l *relatedLookup) workloadSections(workload *unstructured.Unstructured) ([]*relatedSection, error) {
+	owners, err := l.ownerSection(workload)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &relatedSection{title: "Pods"}
+	selector, err := workloadSelector(workload)
+	if err != nil {
+		return nil, err
+	}
+	if selector != nil && !selector.Empty() {
+		pods.title = fmt.Sprintf("Pods selected by %s", selector)
+		if pods.infos, err = l.list(pods, schema.GroupKind{Kind: "Pod"}, workload.GetNamespace(), selector.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	path := podTemplatePath(workload.GroupVersionKind().GroupKind())
+	spec, _, _ := unstructured.NestedMap(workload.Object, append(path, "spec")...)
+	sections, err := l.podSpecSections(workload.GetNamespace(), spec)
+	if err != nil {
+		return nil, err
+	}
+	templateLabels, _, _ := unstructured.NestedStringMap(workload.Object, append(path, "metadata", "labels")...)
+	services, err := l.selectingServices(workload.GetNamespace(), templateLabels)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]*relatedSection{owners, pods}, sections...), services), nil
+}
+
+// podTemplatePath returns the path of the Pod template of a workload kind, or
+// nil for other kinds.
+func podTemplatePath(gk schema.GroupKind) []string {
+	switch gk {
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		schema.GroupKind{Group: "apps", Kind: "ReplicaSet"},
+		schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+		schema.GroupKind{Group: "apps", Kind: "DaemonSet"},
+		schema.GroupKind{Group: "extensions", Kind: "Deployment"},
+		schema.GroupKind{Group: "extensions", Kind: "ReplicaSet"},
+		schema.GroupKind{Group: "extensions", Kind: "DaemonSet"},
+		schema.GroupKind{Group: "batch", Kind: "Job"},
+		schema.GroupKind{Kind: "ReplicationController"}:
+		return []string{"spec", "template"}
+	case schema.GroupKind{Group: "batch", Kind: "CronJob"}:
+		return []string{"spec", "jobTemplate", "spec", "template"}
+	}
+	return nil
+}
+
+// workloadSelector returns the selector of the Pods of a workload, or nil
+// when it has none, like a CronJob.
+func workloadSelector(workload *unstructured.Unstructured) (labels.Selector, error) {
+	if workload.GetKind() == "ReplicationController" {
+		selector, _, _ := unstructured.NestedStringMap(workload.Object, "spec", "selector")
+		return labels.SelectorFromSet(selector), nil
+	}
+	raw, ok, _ := unstructured.NestedMap(workload.Object, "spec", "selector")
+	if !ok {
+		return nil, nil
+	}
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, selector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// ownerSection returns the objects listed in the ownerReferences of obj.
+func (l *relatedLookup) ownerSection(obj *unstructured.Unstructured) (*relatedSection, error) {
+	owners := &relatedSection{title: "Owners"}
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			owners.missing = append(owners.missing, fmt.Sprintf("%s %s: %v", ref.Kind, ref.Name, err))
+			continue
+		}
+		if err := l.add(owners, gv.WithKind(ref.Kind).GroupKind(), obj.GetNamespace(), ref.Name); err != nil {
+			return nil, err
+		}
+	}
+	return owners, nil
+}
+
+// podSpecSections returns the ConfigMaps, Secrets and PersistentVolumeClaims
+// a Pod spec mounts or reads its environment from, and its ServiceAccount.
+func (l *relatedLookup) podSpecSections(namespace string, spec map[string]interface{}) ([]*relatedSection, error) {
+	refs := podSpecReferences(spec)
+	var sections []*relatedSection
+	for _, kind := range []string{"ConfigMap", "Secret", "PersistentVolumeClaim", "ServiceAccount"} {
+		s := &relatedSection{title: kind + "s"}
+		for _, name := range refs[kind].List() {
+			if err := l.add(s, schema.GroupKind{Kind: kind}, namespace, name); err != nil {
+				return nil, err
+			}
+		}
+		sections = append(sections, s)
+	}
+	return sections, nil
+}
+
+// podSpecReferences returns the names of the objects a Pod spec refers to by
+// kind.
+func podSpecReferences(spec map[string]interface{}) map[string]sets.String {
+	refs := map[string]sets.String{
+		"ConfigMap":             sets.NewString(),
+		"Secret":                sets.NewString(),
+		"PersistentVolumeClaim": sets.NewString(),
+		"ServiceAccount":        sets.NewString(),
+	}
+	add := func(kind string, obj map[string]interface{}, fields ...string) {
+		if name, _, _ := unstructured.NestedString(obj, fields...); len(name) > 0 {
+			refs[kind].Insert(name)
+		}
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(spec, "volumes")
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		add("ConfigMap", volume, "configMap", "name")
+		add("Secret", volume, "secret", "secretName")
+		add("PersistentVolumeClaim", volume, "persistentVolumeClaim", "claimName")
+		sources, _, _ := unstructured.NestedSlice(volume, "projected", "sources")
+		for _, s := range sources {
+			source, _ := s.(map[string]interface{})
+			add("ConfigMap", source, "configMap", "name")
+			add("Secret", source, "secret", "name")
+		}
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(spec, field)
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			for _, e := range env {
+				variable, _ := e.(map[string]interface{})
+				add("ConfigMap", variable, "valueFrom", "configMapKeyRef", "name")
+				add("Secret", variable, "valueFrom", "secretKeyRef", "name")
+			}
+			envFrom, _, _ := unstructured.NestedSlice(container, "envFrom")
+			for _, e := range envFrom {
+				source, _ := e.(map[string]interface{})
+				add("ConfigMap", source, "configMapRef", "name")
+				add("Secret", source, "secretRef", "name")
+			}
+		}
+	}
+	pullSecrets, _, _ := unstructured.NestedSlice(spec, "imagePullSecrets")
+	for _, s := range pullSecrets {
+		secret, _ := s.(map[string]interface{})
+		add("Secret", secret, "name")
+	}
+	add("ServiceAccount", spec, "serviceAccountName")
+	if refs["ServiceAccount"].Len() == 0 {
+		add("ServiceAccount", spec, "serviceAccount")
+	}
+	if refs["ServiceAccount"].Len() == 0 {
+		refs["ServiceAccount"].Insert("default")
+	}
+	return refs
+}
+
+// selectingServices returns the Services of namespace whose selector matches
+// podLabels.
+func (l *relatedLookup) selectingServices(namespace string, podLabels map[string]string) (*relatedSection, error) {
+	services := &relatedSection{title: "Services selecting the Pods"}
+	infos, err := l.list(services, schema.GroupKind{Kind: "Service"}, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		selector, _, _ := unstructured.NestedStringMap(info.Object.(*unstructured.Unstructured).Object, "spec", "selector")
+		if len(selector) > 0 && labels.SelectorFromSet(selector).Matches(labels.Set(podLabels)) {
+			services.infos = append(services.infos, info)
+		}
+	}
+	return services, nil
+}
+
+// add reads the named object into s. An object the server refuses to return,
+// for instance because it does not exist, is recorded as missing.
+func (l *relatedLookup) add(s *relatedSection, gk schema.GroupKind, namespace, name string) error {
+	mapping, err := l.mapper.RESTMapping(gk)
+	if err != nil {
+		s.missing = append(s.missing, fmt.Sprintf("%s %q: %v", gk.Kind, name, err))
+		return nil
+	}
+	r := l.client.Resource(mapping.Resource)
+	var obj *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		obj, err = r.Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	} else {
+		obj, err = r.Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	if _, ok := err.(apierrors.APIStatus); ok {
+		s.missing = append(s.missing, err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.infos = append(s.infos, &resource.Info{Mapping: mapping, Namespace: obj.GetNamespace(), Name: obj.GetName(), Object: obj})
+	return nil
+}
+
+// list lists the objects of gk in namespace matching selector, sorted by
+// name. A list the server refuses, for instance because it is forbidden, is
+// recorded as missing in s like in add.
+func (l *relatedLookup) list(s *relatedSection, gk schema.GroupKind, namespace, selector string) ([]*resource.Info, error) {
+	mapping, err := l.mapper.RESTMapping(gk)
+	if err != nil {
+		s.missing = append(s.missing, fmt.Sprintf("%s: %v", gk.Kind, err))
+		return nil, nil
+	}
+	list, err := l.client.Resource(mapping.Resource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if _, ok := err.(apierrors.APIStatus); ok {
+		s.missing = append(s.missing, err.Error())
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	infos := make([]*resource.Info, 0, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		infos = append(infos, &resource.Info{Mapping: mapping, Namespace: obj.GetNamespace(), Name: obj.GetName(), Object: obj})
+	}
+	return infos, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// fromYAML decodes a YAML document into the map of an unstructured object.
+func fromYAML(t *testing.T, doc string) map[string]interface{} {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+		t.Fatal(err)
+	}
+	return runtime.DeepCopyJSON(obj)
+}
+
+func TestPodSpecReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[string][]string
+	}{
+		{
+			name: "empty",
+			spec: "{}",
+			expected: map[string][]string{
+				"ConfigMap":             {},
+				"Secret":                {},
+				"PersistentVolumeClaim": {},
+				"ServiceAccount":        {"default"},
+			},
+		},
+		{
+			name: "volumes and environment",
+			spec: `
+serviceAccountName: web
+imagePullSecrets:
+- name: registry
+volumes:
+- name: config
+  configMap:
+    name: web-config
+- name: tls
+  secret:
+    secretName: web-tls
+- name: data
+  persistentVolumeClaim:
+    claimName: web-data
+- name: projected
+  projected:
+    sources:
+    - configMap:
+        name: ca
+    - secret:
+        name: token
+initContainers:
+- name: init
+  envFrom:
+  - configMapRef:
+      name: init-env
+containers:
+- name: web
+  env:
+  - name: A
+    value: a
+  - name: B
+    valueFrom:
+      configMapKeyRef:
+        name: web-config
+        key: b
+  - name: C
+    valueFrom:
+      secretKeyRef:
+        name: web-credentials
+        key: c
+  envFrom:
+  - secretRef:
+      name: web-env
+`,
+			expected: map[string][]string{
+				"ConfigMap":             {"ca", "init-env", "web-config"},
+				"Secret":                {"registry", "token", "web-credentials", "web-env", "web-tls"},
+				"PersistentVolumeClaim": {"web-data"},
+				"ServiceAccount":        {"web"},
+			},
+		},
+		{
+			name: "deprecated serviceAccount",
+			spec: "serviceAccount: legacy",
+			expected: map[string][]string{
+				"ConfigMap":             {},
+				"Secret":                {},
+				"PersistentVolumeClaim": {},
+				"ServiceAccount":        {"legacy"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refs := podSpecReferences(fromYAML(t, test.spec))
+			names := map[string][]string{}
+			for kind, set := range refs {
+				names[kind] = set.List()
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestIngressBackends(t *testing.T) {
+	tests := []struct {
+		name     string
+		ingress  string
+		expected []string
+	}{
+		{
+			name: "networking.k8s.io/v1",
+			ingress: `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+spec:
+  defaultBackend:
+    service:
+      name: default
+      port:
+        number: 80
+  rules:
+  - host: a.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 80
+      - path: /api
+        pathType: Prefix
+        backend:
+          service:
+            name: api
+            port:
+              name: http
+  - host: b.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          resource:
+            apiGroup: example.com
+            kind: Bucket
+            name: static
+`,
+			expected: []string{"api", "default", "web"},
+		},
+		{
+			name: "extensions/v1beta1",
+			ingress: `
+apiVersion: extensions/v1beta1
+kind: Ingress
+spec:
+  backend:
+    serviceName: default
+    servicePort: 80
+  rules:
+  - http:
+      paths:
+      - backend:
+          serviceName: web
+          servicePort: 80
+`,
+			expected: []string{"default", "web"},
+		},
+		{
+			name: "no rules",
+			ingress: `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+spec: {}
+`,
+			expected: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ingress := &unstructured.Unstructured{Object: fromYAML(t, test.ingress)}
+			if names := ingressBackends(ingress).List(); !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
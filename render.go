@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewRenderCommand returns a command printing objects read from stdin, such
+// as saved "kubectl get -o json" output, as tables without contacting a
+// server.
+func NewRenderCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
+	o.Local = true
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "render demo",
+		Long:  "Render JSON or YAML objects, multi-document streams and Lists read from stdin (or -f/-k) as tables, without contacting a server.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(o.Filenames) == 0 && len(o.Kustomize) == 0 {
+				o.Filenames = []string{"-"}
+			}
+			if err := o.Complete(f, cmd, args); err != nil {
+				return err
+			}
+			return o.Run(f, cmd, args)
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().StringSliceVar(&o.CRDFilenames, "crd", o.CRDFilenames, "Path to a CustomResourceDefinition manifest whose additionalPrinterColumns are used to print its custom resources. Can be repeated.")
+	addColumnsFlag(cmd, o)
+	addWhereFlag(cmd, o)
+	addGroupByFlags(cmd, o)
+	addColorFlag(cmd, o)
+	addPrintersFlags(cmd, o)
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "containing the objects to render. Defaults to stdin.")
+
+	return cmd
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// RowsPrintFlags provides the printer streaming the rows of Tables as
+// newline delimited JSON.
+type RowsPrintFlags struct {
+	Kind schema.GroupKind
+}
+
+// NewRowsPrintFlags returns flags associated with jsonl-rows printing, with
+// default values set.
+func NewRowsPrintFlags() *RowsPrintFlags {
+	return &RowsPrintFlags{}
+}
+
+// AllowedFormats returns the output formats handled by RowsPrintFlags.
+func (f *RowsPrintFlags) AllowedFormats() []string {
+	return []string{"jsonl-rows"}
+}
+
+// Allows reports whether outputFormat is handled by RowsPrintFlags.
+func (f *RowsPrintFlags) Allows(outputFormat string) bool {
+	return outputFormat == "jsonl-rows"
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// streaming rows in that format.
+func (f *RowsPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	if !f.Allows(outputFormat) {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+	return &RowsPrinter{Kind: f.Kind}, nil
+}
+
+// RowsPrinter writes every row of a Table as one JSON object holding the
+// kind, namespace and name of the object and its cells, an object keyed by
+// column name in column order, so that columns like NAME do not clash with
+// the metadata. Cells keep their type. Rows of a WatchEvent also carry the
+// event type.
+type RowsPrinter struct {
+	// Kind is reported for rows whose object does not tell its kind, like
+	// the metadata returned with server-side Tables.
+	Kind schema.GroupKind
+}
+
+var _ printers.ResourcePrinter = &RowsPrinter{}
+
+// PrintObj writes the rows of a Table, or of the Table wrapped by a
+// WatchEvent, one per line.
+func (p *RowsPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	eventType := ""
+	if event, ok := obj.(*metav1.WatchEvent); ok {
+		eventType = event.Type
+		obj = event.Object.Object
+	}
+	table, ok := obj.(*metav1.Table)
+	if !ok {
+		return fmt.Errorf("unable to print %T as table rows", obj)
+	}
+
+	for _, row := range table.Rows {
+		line := &orderedObject{}
+		if len(eventType) > 0 {
+			line.add("event", eventType)
+		}
+		kind, namespace, name := p.Kind.Kind, "", ""
+		if row.Object.Object != nil {
+			if k := row.Object.Object.GetObjectKind().GroupVersionKind().Kind; len(k) > 0 && k != "PartialObjectMetadata" {
+				kind = k
+			}
+			if m, err := meta.Accessor(row.Object.Object); err == nil {
+				namespace, name = m.GetNamespace(), m.GetName()
+			}
+		}
+		line.add("kind", kind)
+		line.add("namespace", namespace)
+		line.add("name", name)
+		cells := &orderedObject{}
+		for i, cell := range row.Cells {
+			if i >= len(table.ColumnDefinitions) {
+				break
+			}
+			cells.add(table.ColumnDefinitions[i].Name, cell)
+		}
+		line.add("cells", cells)
+		data, err := line.bytes()
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderedObject is a JSON object that keeps its keys in insertion order.
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *orderedObject) add(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON encodes o nested in another orderedObject.
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	return o.bytes()
+}
+
+func (o *orderedObject) bytes() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := encodeJSON(&b, key); err != nil {
+			return nil, err
+		}
+		b.WriteByte(':')
+		if err := encodeJSON(&b, o.values[i]); err != nil {
+			return nil, fmt.Errorf("unable to encode column %q: %v", key, err)
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// encodeJSON writes v to b without escaping cells like <none> for html.
+func encodeJSON(b *bytes.Buffer, v interface{}) error {
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	// drop the newline written by Encode
+	b.Truncate(b.Len() - 1)
+	return nil
+}
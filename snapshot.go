@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	snapshotAPIVersion = "kget.io/v1"
+	snapshotKind       = "Snapshot"
+)
+
+// snapshot records the objects returned by a query together with the Tables
+// generated for them.
+type snapshot struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Time       metav1.Time         `json:"time"`
+	Query      snapshotQuery       `json:"query"`
+	Resources  []*snapshotResource `json:"resources"`
+}
+
+// snapshotQuery is what was asked for, so that it can be asked again.
+type snapshotQuery struct {
+	Args              []string `json:"args,omitempty"`
+	Filenames         []string `json:"filenames,omitempty"`
+	Recursive         bool     `json:"recursive,omitempty"`
+	Namespace         string   `json:"namespace,omitempty"`
+	ExplicitNamespace bool     `json:"explicitNamespace,omitempty"`
+	AllNamespaces     bool     `json:"allNamespaces,omitempty"`
+	LabelSelector     string   `json:"labelSelector,omitempty"`
+	FieldSelector     string   `json:"fieldSelector,omitempty"`
+}
+
+// snapshotResource holds the Table of one resource, with the object of every
+// row.
+type snapshotResource struct {
+	Group    string        `json:"group,omitempty"`
+	Version  string        `json:"version"`
+	Kind     string        `json:"kind"`
+	Resource string        `json:"resource"`
+	Table    *metav1.Table `json:"table"`
+}
+
+func (r *snapshotResource) String() string {
+	if len(r.Group) == 0 {
+		return r.Resource
+	}
+	return r.Resource + "." + r.Group
+}
+
+// NewSnapshotCommand returns a command saving the result of a query to a
+// file and comparing saved results with each other or with the cluster.
+func NewSnapshotCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "snapshot demo",
+		Long:  "Save the objects and tables returned by a query, and report what changed between two snapshots or since a snapshot was saved.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(newSnapshotSaveCommand(f, configFlags))
+	cmd.AddCommand(newSnapshotDiffCommand(f, configFlags))
+	return cmd
+}
+
+func newSnapshotSaveCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
+	cmd := &cobra.Command{
+		Use:   "save FILE (TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...)",
+		Short: "snapshot save demo",
+		Long:  "Save the objects returned by a query, as kget get would list them, to FILE together with their tables.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, cmd, args[1:]); err != nil {
+				return err
+			}
+			s, err := o.takeSnapshot(f, args[1:])
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(args[0], append(data, '\n'), 0644); err != nil {
+				return err
+			}
+			objects := 0
+			for _, r := range s.Resources {
+				objects += len(r.Table.Rows)
+			}
+			fmt.Fprintf(o.Out, "Saved %d objects of %d resources to %s\n", objects, len(s.Resources), args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, save the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.ServerPrint, "server-print", o.ServerPrint, "If true, have the server return the appropriate table output. Supports extension APIs and CRDs. Falls back to client-side printing when the server cannot produce a table.")
+	cmd.Flags().StringSliceVar(&o.CRDFilenames, "crd", o.CRDFilenames, "Path to a CustomResourceDefinition manifest whose additionalPrinterColumns are used to print its custom resources instead of reading the definition from the server. Can be repeated.")
+	addPrintersFlags(cmd, o)
+	cmd.Flags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize, "Return large lists in chunks rather than all at once. Pass 0 to disable.")
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to save from a server.")
+
+	return cmd
+}
+
+func newSnapshotDiffCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
+	live, objects := false, false
+	cmd := &cobra.Command{
+		Use:   "diff BEFORE (AFTER | --live)",
+		Short: "snapshot diff demo",
+		Long:  "Report the objects added, removed and changed between two snapshots, or since a snapshot was saved with --live, with the cells of their tables that changed.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if live != (len(args) == 1) {
+				return fmt.Errorf("expected two snapshots, or one snapshot and --live")
+			}
+			before, err := readSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			after, name := (*snapshot)(nil), "the cluster"
+			if live {
+				if err := o.Complete(f, cmd, nil); err != nil {
+					return err
+				}
+				o.applyQuery(before.Query)
+				if after, err = o.takeSnapshot(f, before.Query.Args); err != nil {
+					return err
+				}
+			} else {
+				if after, err = readSnapshot(args[1]); err != nil {
+					return err
+				}
+				name = fmt.Sprintf("%s (saved %s)", args[1], after.Time.UTC().Format(time.RFC3339))
+			}
+			fmt.Fprintf(o.Out, "Comparing %s (saved %s) with %s\n", args[0], before.Time.UTC().Format(time.RFC3339), name)
+			return diffSnapshots(o.Out, before, after, objects)
+		},
+	}
+
+	cmd.Flags().BoolVar(&live, "live", live, "If true, compare the snapshot with the result of its query run against the cluster now.")
+	cmd.Flags().BoolVar(&objects, "objects", objects, "If true, also print a diff of the full objects that changed, ignoring their resourceVersion and managedFields.")
+	cmd.Flags().StringSliceVar(&o.CRDFilenames, "crd", o.CRDFilenames, "Path to a CustomResourceDefinition manifest whose additionalPrinterColumns are used to print its custom resources instead of reading the definition from the server. Can be repeated.")
+	addPrintersFlags(cmd, o)
+
+	return cmd
+}
+
+// takeSnapshot lists the requested objects into a snapshot, one Table per
+// resource. The rows keep their full objects for --objects, rather than the
+// metadata the server includes by default.
+func (o *GetOptions) takeSnapshot(f cmdutil.Factory, args []string) (*snapshot, error) {
+	o.includeObject = true
+	s := &snapshot{
+		APIVersion: snapshotAPIVersion,
+		Kind:       snapshotKind,
+		Time:       metav1.Now(),
+		Query: snapshotQuery{
+			Args:              args,
+			Filenames:         o.Filenames,
+			Recursive:         o.Recursive,
+			Namespace:         o.Namespace,
+			ExplicitNamespace: o.ExplicitNamespace,
+			AllNamespaces:     o.AllNamespaces,
+			LabelSelector:     o.LabelSelector,
+			FieldSelector:     o.FieldSelector,
+		},
+	}
+	converter := o.newTableConverter()
+	resources := map[string]*snapshotResource{}
+	err := o.newResult(f, o.Namespace, o.ExplicitNamespace, args).Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		table, err := converter.ToTable(info.Object)
+		if err != nil {
+			return err
+		}
+		key := mappingKey(info.Mapping)
+		r, ok := resources[key]
+		if !ok {
+			gvk := info.Mapping.GroupVersionKind
+			r = &snapshotResource{
+				Group:    gvk.Group,
+				Version:  gvk.Version,
+				Kind:     gvk.Kind,
+				Resource: info.Mapping.Resource.Resource,
+				Table:    &metav1.Table{ColumnDefinitions: table.ColumnDefinitions, Rows: []metav1.TableRow{}},
+			}
+			resources[key] = r
+			s.Resources = append(s.Resources, r)
+		}
+		r.Table.Rows = append(r.Table.Rows, table.Rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// applyQuery makes o ask for what query asked for.
+func (o *GetOptions) applyQuery(query snapshotQuery) {
+	o.Filenames = query.Filenames
+	o.Recursive = query.Recursive
+	o.Namespace = query.Namespace
+	o.ExplicitNamespace = query.ExplicitNamespace
+	o.AllNamespaces = query.AllNamespaces
+	o.LabelSelector = query.LabelSelector
+	o.FieldSelector = query.FieldSelector
+}
+
+// readSnapshot reads the snapshot saved to filename, decoding the objects of
+// its rows.
+func readSnapshot(filename string) (*snapshot, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s := &snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if s.APIVersion != snapshotAPIVersion || s.Kind != snapshotKind {
+		return nil, fmt.Errorf("%s: not a snapshot saved by kget snapshot save", filename)
+	}
+	for _, r := range s.Resources {
+		if r.Table == nil {
+			return nil, fmt.Errorf("%s: %s has no table", filename, r)
+		}
+		for i := range r.Table.Rows {
+			row := &r.Table.Rows[i]
+			if row.Object.Raw == nil || string(row.Object.Raw) == "null" {
+				continue
+			}
+			obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, row.Object.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", filename, err)
+			}
+			row.Object.Object = obj
+		}
+	}
+	return s, nil
+}
+
+// snapshotRow is a row of a snapshot, identified by the namespace and name of
+// its object.
+type snapshotRow struct {
+	key     string
+	cells   map[string]string
+	columns []string
+	object  *unstructured.Unstructured
+}
+
+// snapshotRows returns the rows of r by key, and their keys in order.
+func snapshotRows(r *snapshotResource) (map[string]*snapshotRow, []string) {
+	rows := map[string]*snapshotRow{}
+	var keys []string
+	if r == nil {
+		return rows, nil
+	}
+	nameColumn := -1
+	for i, column := range r.Table.ColumnDefinitions {
+		if column.Format == "name" {
+			nameColumn = i
+			break
+		}
+	}
+	for _, row := range r.Table.Rows {
+		sr := &snapshotRow{cells: map[string]string{}}
+		if u, ok := row.Object.Object.(*unstructured.Unstructured); ok {
+			sr.object = u
+			sr.key = u.GetName()
+			if len(u.GetNamespace()) > 0 {
+				sr.key = u.GetNamespace() + "/" + sr.key
+			}
+		} else if nameColumn != -1 {
+			sr.key = fmt.Sprint(cellAt(row.Cells, nameColumn))
+		}
+		for i, column := range r.Table.ColumnDefinitions {
+			// ages change all the time
+			if isAgeColumn(column) {
+				continue
+			}
+			name := strings.ToUpper(column.Name)
+			sr.columns = append(sr.columns, name)
+			sr.cells[name] = fmt.Sprint(cellAt(row.Cells, i))
+		}
+		if _, ok := rows[sr.key]; !ok {
+			keys = append(keys, sr.key)
+		}
+		rows[sr.key] = sr
+	}
+	sort.Strings(keys)
+	return rows, keys
+}
+
+// diffSnapshots writes the objects added, removed and changed between before
+// and after, resource by resource.
+func diffSnapshots(w io.Writer, before, after *snapshot, objects bool) error {
+	var order []string
+	resources := map[string][2]*snapshotResource{}
+	for i, s := range []*snapshot{before, after} {
+		for _, r := range s.Resources {
+			key := r.String()
+			pair, ok := resources[key]
+			if !ok {
+				order = append(order, key)
+			}
+			pair[i] = r
+			resources[key] = pair
+		}
+	}
+
+	differences := false
+	for _, key := range order {
+		oldRows, oldKeys := snapshotRows(resources[key][0])
+		newRows, newKeys := snapshotRows(resources[key][1])
+		var added, removed, changed []string
+		for _, k := range newKeys {
+			if _, ok := oldRows[k]; !ok {
+				added = append(added, k)
+			}
+		}
+		for _, k := range oldKeys {
+			newRow, ok := newRows[k]
+			switch {
+			case !ok:
+				removed = append(removed, k)
+			case len(changedCells(oldRows[k], newRow)) > 0 || objects && len(objectDiff(oldRows[k], newRow)) > 0:
+				changed = append(changed, k)
+			}
+		}
+		if len(added)+len(removed)+len(changed) == 0 {
+			continue
+		}
+		differences = true
+
+		fmt.Fprintf(w, "\n%s: %d added, %d removed, %d changed\n", key, len(added), len(removed), len(changed))
+		for _, k := range added {
+			fmt.Fprintf(w, "  + %s\n", k)
+		}
+		for _, k := range removed {
+			fmt.Fprintf(w, "  - %s\n", k)
+		}
+		for _, k := range changed {
+			fmt.Fprintf(w, "  ~ %s\n", k)
+			for _, cell := range changedCells(oldRows[k], newRows[k]) {
+				fmt.Fprintf(w, "      %s\n", cell)
+			}
+			if !objects {
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimRight(objectDiff(oldRows[k], newRows[k]), "\n"), "\n") {
+				if len(line) > 0 {
+					fmt.Fprintf(w, "      %s\n", line)
+				}
+			}
+		}
+	}
+	if !differences {
+		fmt.Fprintln(w, "No differences found.")
+	}
+	return nil
+}
+
+// changedCells describes the cells that differ between two rows, like
+// "STATUS: Running -> Failed".
+func changedCells(before, after *snapshotRow) []string {
+	var changes []string
+	for _, name := range after.columns {
+		old, ok := before.cells[name]
+		if !ok {
+			// a column that was not printed before is not a change of the object
+			continue
+		}
+		if value := after.cells[name]; value != old {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, old, value))
+		}
+	}
+	return changes
+}
+
+// objectDiff returns the differences between the objects of two rows,
+// ignoring their resourceVersion and managedFields, or "" if there are none.
+func objectDiff(before, after *snapshotRow) string {
+	if before.object == nil || after.object == nil {
+		return ""
+	}
+	a, b := comparableObject(before.object), comparableObject(after.object)
+	if reflect.DeepEqual(a, b) {
+		return ""
+	}
+	return diff.ObjectReflectDiff(a, b)
+}
+
+func comparableObject(obj *unstructured.Unstructured) map[string]interface{} {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	return obj.Object
+}
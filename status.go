@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The statuses computeStatus derives for objects without a dedicated printer.
+const (
+	statusCurrent     = "Current"
+	statusInProgress  = "InProgress"
+	statusFailed      = "Failed"
+	statusTerminating = "Terminating"
+	statusUnknown     = "Unknown"
+)
+
+var (
+	currentPhases    = []string{"Active", "Available", "Bound", "Complete", "Completed", "Healthy", "Ready", "Running", "Succeeded"}
+	inProgressPhases = []string{"Creating", "Deploying", "Initializing", "Pending", "Progressing", "Provisioning", "Reconciling", "Updating"}
+	failedPhases     = []string{"Error", "Failed", "Failure", "Lost", "Unhealthy"}
+)
+
+// computeStatus derives one of Current, InProgress, Failed, Terminating or
+// Unknown for an arbitrary object from its deletionTimestamp, its
+// observedGeneration, the conventional status.conditions and status.phase,
+// together with a short reason.
+func computeStatus(obj *unstructured.Unstructured) (string, string) {
+	if obj.GetDeletionTimestamp() != nil {
+		return statusTerminating, "DeletionRequested"
+	}
+
+	status, found, _ := unstructured.NestedMap(obj.Object, "status")
+	if !found || len(status) == 0 {
+		// objects without a status have nothing to reconcile
+		return statusCurrent, ""
+	}
+
+	if observed, found, _ := unstructured.NestedInt64(status, "observedGeneration"); found && observed < obj.GetGeneration() {
+		return statusInProgress, fmt.Sprintf("Generation%dNotObserved", obj.GetGeneration())
+	}
+
+	conditions := statusConditions(status)
+	for _, t := range []string{"Failed", "Stalled"} {
+		if c, ok := conditions[t]; ok && c.status == "True" {
+			return statusFailed, c.reasonOr(t)
+		}
+	}
+	for _, t := range []string{"Ready", "Available"} {
+		c, ok := conditions[t]
+		if !ok {
+			continue
+		}
+		switch c.status {
+		case "True":
+			return statusCurrent, ""
+		case "False":
+			return statusInProgress, c.reasonOr("Not" + t)
+		default:
+			return statusUnknown, c.reasonOr(t + "Unknown")
+		}
+	}
+	if c, ok := conditions["Progressing"]; ok && c.status == "True" {
+		return statusInProgress, c.reasonOr("Progressing")
+	}
+
+	if phase, found, _ := unstructured.NestedString(status, "phase"); found && len(phase) > 0 {
+		switch {
+		case containsFold(failedPhases, phase):
+			return statusFailed, phase
+		case containsFold(inProgressPhases, phase):
+			return statusInProgress, phase
+		case containsFold(currentPhases, phase):
+			return statusCurrent, phase
+		}
+		return statusUnknown, phase
+	}
+
+	if len(conditions) > 0 {
+		return statusCurrent, ""
+	}
+	return statusUnknown, ""
+}
+
+type condition struct {
+	status string
+	reason string
+}
+
+func (c condition) reasonOr(reason string) string {
+	if len(c.reason) > 0 {
+		return c.reason
+	}
+	return reason
+}
+
+// statusConditions indexes status.conditions by type.
+func statusConditions(status map[string]interface{}) map[string]condition {
+	conditions := map[string]condition{}
+	items, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, item := range items {
+		c, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		t, _, _ := unstructured.NestedString(c, "type")
+		s, _, _ := unstructured.NestedString(c, "status")
+		reason, _, _ := unstructured.NestedString(c, "reason")
+		conditions[t] = condition{status: s, reason: reason}
+	}
+	return conditions
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
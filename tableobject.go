@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// TablePrintFlags provides the printers serializing the generated Tables
+// themselves as meta.k8s.io/v1 Table objects.
+type TablePrintFlags struct {
+	IncludeObject *bool
+
+	// yamlPrinter is shared by the printers of every kind, for it to write
+	// --- between their documents
+	yamlPrinter *printers.YAMLPrinter
+}
+
+// NewTablePrintFlags returns flags associated with table-json and table-yaml
+// printing, with default values set.
+func NewTablePrintFlags() *TablePrintFlags {
+	includeObject := false
+	return &TablePrintFlags{IncludeObject: &includeObject}
+}
+
+// AllowedFormats returns the output formats handled by TablePrintFlags.
+func (f *TablePrintFlags) AllowedFormats() []string {
+	return []string{"table-json", "table-yaml"}
+}
+
+// Allows reports whether outputFormat is handled by TablePrintFlags.
+func (f *TablePrintFlags) Allows(outputFormat string) bool {
+	return outputFormat == "table-json" || outputFormat == "table-yaml"
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// serializing Tables in that format.
+func (f *TablePrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	p := &TableObjectPrinter{IncludeObject: f.IncludeObject != nil && *f.IncludeObject}
+	switch outputFormat {
+	case "table-json":
+		p.Delegate = &printers.JSONPrinter{}
+	case "table-yaml":
+		if f.yamlPrinter == nil {
+			f.yamlPrinter = &printers.YAMLPrinter{}
+		}
+		p.Delegate = f.yamlPrinter
+	default:
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+	return p, nil
+}
+
+// AddFlags receives a *cobra.Command reference and binds flags related to
+// Table serialization to it.
+func (f *TablePrintFlags) AddFlags(c *cobra.Command) {
+	if f.IncludeObject != nil {
+		c.Flags().BoolVar(f.IncludeObject, "include-object", *f.IncludeObject, "When using the table-json or table-yaml output format, keep the full object of every row in rows[].object.")
+	}
+}
+
+// TableObjectPrinter serializes Tables as meta.k8s.io/v1 Table objects that
+// can be read back with -f. Consecutive Tables with the same columns, like
+// the chunks of a list, are merged into one; call Close to write it.
+type TableObjectPrinter struct {
+	Delegate      printers.ResourcePrinter
+	IncludeObject bool
+
+	table *metav1.Table
+}
+
+var _ printers.ResourcePrinter = &TableObjectPrinter{}
+
+// PrintObj adds the rows of a Table to the Table being built, writing the
+// previous one first if the columns changed. A WatchEvent is written at once,
+// wrapping its Table.
+func (p *TableObjectPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	if event, ok := obj.(*metav1.WatchEvent); ok {
+		table, ok := event.Object.Object.(*metav1.Table)
+		if !ok {
+			return fmt.Errorf("unable to print %T as a table", event.Object.Object)
+		}
+		u, err := p.toUnstructured(table)
+		if err != nil {
+			return err
+		}
+		return p.Delegate.PrintObj(&metav1.WatchEvent{Type: event.Type, Object: runtime.RawExtension{Object: u}}, w)
+	}
+	table, ok := obj.(*metav1.Table)
+	if !ok {
+		return fmt.Errorf("unable to print %T as a table", obj)
+	}
+
+	if p.table != nil && !reflect.DeepEqual(p.table.ColumnDefinitions, table.ColumnDefinitions) {
+		if err := p.Close(w); err != nil {
+			return err
+		}
+	}
+	if p.table == nil {
+		p.table = &metav1.Table{ColumnDefinitions: table.ColumnDefinitions}
+	}
+	p.table.Rows = append(p.table.Rows, table.Rows...)
+	p.table.ResourceVersion = table.ResourceVersion
+	return nil
+}
+
+// Close writes the Table being built, if any.
+func (p *TableObjectPrinter) Close(w io.Writer) error {
+	if p.table == nil {
+		return nil
+	}
+	table := p.table
+	p.table = nil
+	u, err := p.toUnstructured(table)
+	if err != nil {
+		return err
+	}
+	return p.Delegate.PrintObj(u, w)
+}
+
+// toUnstructured returns table as a meta.k8s.io/v1 Table, dropping the
+// objects of its rows unless IncludeObject is set.
+func (p *TableObjectPrinter) toUnstructured(table *metav1.Table) (*unstructured.Unstructured, error) {
+	// cells may hold values DeepCopy does not know, the copy is not modified
+	copied := *table
+	table = &copied
+	table.SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("Table"))
+	if table.ColumnDefinitions == nil {
+		table.ColumnDefinitions = []metav1.TableColumnDefinition{}
+	}
+	if table.Rows == nil {
+		table.Rows = []metav1.TableRow{}
+	}
+	data, err := json.Marshal(table)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, data)
+	if err != nil {
+		return nil, err
+	}
+	u := obj.(*unstructured.Unstructured)
+	rows, _, _ := unstructured.NestedSlice(u.Object, "rows")
+	for _, r := range rows {
+		row, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if !p.IncludeObject || row["object"] == nil {
+			delete(row, "object")
+		}
+	}
+	if err := unstructured.SetNestedSlice(u.Object, rows, "rows"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// tabularFormats maps every output format handled by TabularPrinter to its
+// encoding. The -wide variants include the priority columns.
+var tabularFormats = map[string]tableEncoding{
+	"markdown":      markdownEncoding{},
+	"csv":           csvEncoding{},
+	"tsv":           tsvEncoding{},
+	"html":          htmlEncoding{},
+	"markdown-wide": markdownEncoding{},
+	"csv-wide":      csvEncoding{},
+	"tsv-wide":      tsvEncoding{},
+	"html-wide":     htmlEncoding{},
+}
+
+// TabularPrintFlags provides the printers writing Tables as markdown, csv,
+// tsv or html documents.
+type TabularPrintFlags struct {
+	NoHeaders bool
+}
+
+// NewTabularPrintFlags returns flags associated with markdown, csv, tsv and
+// html printing, with default values set.
+func NewTabularPrintFlags() *TabularPrintFlags {
+	return &TabularPrintFlags{}
+}
+
+// AllowedFormats returns the output formats handled by TabularPrintFlags.
+func (f *TabularPrintFlags) AllowedFormats() []string {
+	return []string{"markdown", "csv", "tsv", "html", "markdown-wide", "csv-wide", "tsv-wide", "html-wide"}
+}
+
+// Allows reports whether outputFormat is handled by TabularPrintFlags.
+func (f *TabularPrintFlags) Allows(outputFormat string) bool {
+	_, ok := tabularFormats[outputFormat]
+	return ok
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// writing Tables in that format.
+func (f *TabularPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	encoding, ok := tabularFormats[outputFormat]
+	if !ok {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+	// markdown has no tables without a header row
+	if _, ok := encoding.(markdownEncoding); ok && f.NoHeaders {
+		return nil, fmt.Errorf("--no-headers is not supported with --output=%s", outputFormat)
+	}
+	return &TabularPrinter{
+		encoding:  encoding,
+		noHeaders: f.NoHeaders,
+		wide:      strings.HasSuffix(outputFormat, "-wide"),
+	}, nil
+}
+
+// tableEncoding writes the parts of a document holding a single table.
+type tableEncoding interface {
+	begin(w io.Writer, headers []string, noHeaders bool) error
+	row(w io.Writer, cells []string) error
+	end(w io.Writer) error
+}
+
+// TabularPrinter writes Tables as markdown, csv, tsv or html. Consecutive
+// Tables with the same columns, like the chunks of a list or the events of a
+// watch, are written as a single table; call Close to end it.
+type TabularPrinter struct {
+	encoding  tableEncoding
+	noHeaders bool
+	wide      bool
+
+	open    bool
+	columns []metav1.TableColumnDefinition
+}
+
+var _ printers.ResourcePrinter = &TabularPrinter{}
+
+// PrintObj writes the rows of a Table, or of the Table wrapped by a
+// WatchEvent, starting a new table when the columns changed.
+func (p *TabularPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	eventType := ""
+	if event, ok := obj.(*metav1.WatchEvent); ok {
+		eventType = event.Type
+		obj = event.Object.Object
+	}
+	table, ok := obj.(*metav1.Table)
+	if !ok {
+		return fmt.Errorf("unable to print %T as a table", obj)
+	}
+	// avoid starting a table we have no rows for
+	if len(table.Rows) == 0 {
+		return nil
+	}
+
+	if !p.open || !reflect.DeepEqual(p.columns, table.ColumnDefinitions) {
+		if err := p.Close(w); err != nil {
+			return err
+		}
+		var headers []string
+		if len(eventType) > 0 {
+			headers = append(headers, "EVENT")
+		}
+		for _, column := range table.ColumnDefinitions {
+			if column.Priority != 0 && !p.wide {
+				continue
+			}
+			headers = append(headers, strings.ToUpper(column.Name))
+		}
+		if err := p.encoding.begin(w, headers, p.noHeaders); err != nil {
+			return err
+		}
+		p.open = true
+		p.columns = table.ColumnDefinitions
+	}
+
+	for _, row := range table.Rows {
+		var cells []string
+		if len(eventType) > 0 {
+			cells = append(cells, eventType)
+		}
+		for i, cell := range row.Cells {
+			if i >= len(table.ColumnDefinitions) {
+				break
+			}
+			if table.ColumnDefinitions[i].Priority != 0 && !p.wide {
+				continue
+			}
+			if cell == nil {
+				cells = append(cells, "")
+				continue
+			}
+			cells = append(cells, fmt.Sprint(cell))
+		}
+		if err := p.encoding.row(w, cells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close ends the table being written, if any.
+func (p *TabularPrinter) Close(w io.Writer) error {
+	if !p.open {
+		return nil
+	}
+	p.open = false
+	p.columns = nil
+	return p.encoding.end(w)
+}
+
+// markdownEncoding writes GitHub flavored markdown tables.
+type markdownEncoding struct{}
+
+// markdownEscaper also keeps cells like <none> from being read as html tags.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "<", "&lt;", ">", "&gt;", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func (e markdownEncoding) begin(w io.Writer, headers []string, noHeaders bool) error {
+	if err := e.row(w, headers); err != nil {
+		return err
+	}
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+	return err
+}
+
+func (markdownEncoding) row(w io.Writer, cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = markdownEscaper.Replace(cell)
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+	return err
+}
+
+func (markdownEncoding) end(w io.Writer) error {
+	return nil
+}
+
+// csvEncoding writes RFC 4180 comma separated values.
+type csvEncoding struct{}
+
+func (e csvEncoding) begin(w io.Writer, headers []string, noHeaders bool) error {
+	if noHeaders {
+		return nil
+	}
+	return e.row(w, headers)
+}
+
+func (csvEncoding) row(w io.Writer, cells []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(cells); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (csvEncoding) end(w io.Writer) error {
+	return nil
+}
+
+// tsvEncoding writes tab separated values, escaping tabs, line breaks and
+// backslashes in cells the way PostgreSQL and MySQL text dumps do.
+type tsvEncoding struct{}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (e tsvEncoding) begin(w io.Writer, headers []string, noHeaders bool) error {
+	if noHeaders {
+		return nil
+	}
+	return e.row(w, headers)
+}
+
+func (tsvEncoding) row(w io.Writer, cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = tsvEscaper.Replace(cell)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(escaped, "\t"))
+	return err
+}
+
+func (tsvEncoding) end(w io.Writer) error {
+	return nil
+}
+
+// htmlEncoding writes an html table element.
+type htmlEncoding struct{}
+
+func (e htmlEncoding) begin(w io.Writer, headers []string, noHeaders bool) error {
+	if _, err := fmt.Fprintln(w, "<table>"); err != nil {
+		return err
+	}
+	if noHeaders {
+		return nil
+	}
+	return e.cells(w, "th", headers)
+}
+
+func (e htmlEncoding) row(w io.Writer, cells []string) error {
+	return e.cells(w, "td", cells)
+}
+
+func (htmlEncoding) cells(w io.Writer, tag string, cells []string) error {
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, cell := range cells {
+		fmt.Fprintf(&b, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+	}
+	b.WriteString("</tr>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (htmlEncoding) end(w io.Writer) error {
+	_, err := fmt.Fprintln(w, "</table>")
+	return err
+}
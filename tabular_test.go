@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTabularPrinter(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string"},
+			{Name: "Value", Type: "string"},
+			{Name: "Node", Type: "string", Priority: 1},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"a", `x|y\z`, "n1"}},
+			{Cells: []interface{}{"b", "<none>", nil}},
+			{Cells: []interface{}{"c", "one, \"two\"\nthree\tfour", "n2"}},
+		},
+	}
+	tests := []struct {
+		format    string
+		noHeaders bool
+		obj       runtime.Object
+		expected  string
+	}{
+		{
+			format: "markdown",
+			obj:    table,
+			expected: "| NAME | VALUE |\n" +
+				"| --- | --- |\n" +
+				"| a | x\\|y\\\\z |\n" +
+				"| b | &lt;none&gt; |\n" +
+				"| c | one, \"two\"<br>three\tfour |\n",
+		},
+		{
+			format: "csv",
+			obj:    table,
+			expected: "NAME,VALUE\n" +
+				"a,x|y\\z\n" +
+				"b,<none>\n" +
+				"c,\"one, \"\"two\"\"\nthree\tfour\"\n",
+		},
+		{
+			format:    "csv-wide",
+			noHeaders: true,
+			obj:       table,
+			expected: "a,x|y\\z,n1\n" +
+				"b,<none>,\n" +
+				"c,\"one, \"\"two\"\"\nthree\tfour\",n2\n",
+		},
+		{
+			format: "tsv",
+			obj:    table,
+			expected: "NAME\tVALUE\n" +
+				"a\tx|y\\\\z\n" +
+				"b\t<none>\n" +
+				"c\tone, \"two\"\\nthree\\tfour\n",
+		},
+		{
+			format: "html",
+			obj:    table,
+			expected: "<table>\n" +
+				"<tr><th>NAME</th><th>VALUE</th></tr>\n" +
+				"<tr><td>a</td><td>x|y\\z</td></tr>\n" +
+				"<tr><td>b</td><td>&lt;none&gt;</td></tr>\n" +
+				"<tr><td>c</td><td>one, &#34;two&#34;\nthree\tfour</td></tr>\n" +
+				"</table>\n",
+		},
+		{
+			format: "markdown",
+			obj:    &metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: &metav1.Table{ColumnDefinitions: table.ColumnDefinitions, Rows: table.Rows[:1]}}},
+			expected: "| EVENT | NAME | VALUE |\n" +
+				"| --- | --- | --- |\n" +
+				"| ADDED | a | x\\|y\\\\z |\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			flags := &TabularPrintFlags{NoHeaders: test.noHeaders}
+			printer, err := flags.ToPrinter(test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := printer.PrintObj(test.obj, &out); err != nil {
+				t.Fatal(err)
+			}
+			if err := printer.(*TabularPrinter).Close(&out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTabularPrinterContinuesTable(t *testing.T) {
+	columns := []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}}
+	chunk := func(name string) *metav1.Table {
+		return &metav1.Table{ColumnDefinitions: columns, Rows: []metav1.TableRow{{Cells: []interface{}{name}}}}
+	}
+	printer, err := (&TabularPrintFlags{}).ToPrinter("html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	for _, obj := range []*metav1.Table{chunk("a"), {ColumnDefinitions: columns}, chunk("b")} {
+		if err := printer.PrintObj(obj, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := printer.(*TabularPrinter).Close(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "<table>\n<tr><th>NAME</th></tr>\n<tr><td>a</td></tr>\n<tr><td>b</td></tr>\n</table>\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestTabularPrintFlagsMarkdownNoHeaders(t *testing.T) {
+	if _, err := (&TabularPrintFlags{NoHeaders: true}).ToPrinter("markdown"); err == nil {
+		t.Errorf("expected --no-headers to be rejected with markdown")
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewTreeCommand returns a command printing an object and everything it owns,
+// directly or through other objects, as a tree.
+func NewTreeCommand(f cmdutil.Factory, configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := NewOptions(configFlags)
+	depth, showAll := 0, false
+	cmd := &cobra.Command{
+		Use:   "tree (TYPE[.VERSION][.GROUP] NAME | TYPE[.VERSION][.GROUP]/NAME)",
+		Short: "tree demo",
+		Long:  "Print an object and the objects it owns through their metadata.ownerReferences, like the ReplicaSets and Pods of a Deployment, as a tree with the READY, STATUS and AGE columns of their kinds.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("an object is required, e.g. deployment/web")
+			}
+			if depth < 0 {
+				return fmt.Errorf("--depth must not be negative")
+			}
+			if err := o.Complete(f, cmd, args); err != nil {
+				return err
+			}
+			return o.runTree(f, args, depth, showAll)
+		},
+	}
+
+	cmd.Flags().IntVar(&depth, "depth", depth, "The number of levels of owned objects to print. 0 prints all of them.")
+	cmd.Flags().BoolVar(&showAll, "show-all", showAll, "If true, also print completed Pods and Jobs and ReplicaSets scaled down to zero, along with what they own.")
+	cmd.Flags().IntVar(&o.Concurrency, "concurrency", o.Concurrency, "The maximum number of resource types listed at the same time while looking for owned objects.")
+	cmd.Flags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize, "Return large lists in chunks rather than all at once while looking for owned objects. Pass 0 to disable.")
+	addColorFlag(cmd, o)
+
+	return cmd
+}
+
+// treeNode is an object of the tree with the objects it owns.
+type treeNode struct {
+	object   *unstructured.Unstructured
+	children []*treeNode
+}
+
+// singleObject returns the one object named by args.
+func (o *GetOptions) singleObject(f cmdutil.Factory, args []string) (*unstructured.Unstructured, error) {
+	infos, err := f.NewBuilder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(false, args...).
+		SingleResourceType().
+		Latest().
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) != 1 {
+		return nil, fmt.Errorf("exactly one object is required, %d were found", len(infos))
+	}
+	obj, ok := infos[0].Object.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", infos[0].Object)
+	}
+	return obj, nil
+}
+
+// runTree prints the tree of the single object named by args.
+func (o *GetOptions) runTree(f cmdutil.Factory, args []string, depth int, showAll bool) error {
+	root, err := o.singleObject(f, args)
+	if err != nil {
+		return err
+	}
+
+	// the objects owned by a cluster scoped object may live in any namespace
+	namespace := root.GetNamespace()
+	objects, err := o.listOwnableObjects(f, namespace)
+	if err != nil {
+		return err
+	}
+	b := &treeBuilder{objects: objects, depth: depth, showAll: showAll, owned: map[types.UID][]*unstructured.Unstructured{}, visited: map[types.UID]bool{}}
+	for _, obj := range objects {
+		for _, ref := range obj.GetOwnerReferences() {
+			b.owned[ref.UID] = append(b.owned[ref.UID], obj)
+		}
+	}
+	return o.printTree(b.build(root, 0), len(namespace) == 0)
+}
+
+// unownedResources are the resources whose objects are never owned but may be
+// numerous, and are not listed while looking for owned objects.
+var unownedResources = sets.NewString("events", "events.events.k8s.io")
+
+// listOwnableObjects lists the objects of every listable resource in
+// namespace, or in all namespaces and the cluster scope when namespace is
+// empty, in chunks of --chunk-size. A resource that cannot be listed is
+// skipped.
+func (o *GetOptions) listOwnableObjects(f cmdutil.Factory, namespace string) ([]*unstructured.Unstructured, error) {
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	lists, err := discovery.ServerPreferredResources(discoveryClient)
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		klog.V(2).Infof("Unable to discover all resources: %v", err)
+	}
+	client, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !containsFold(r.Verbs, "list") || len(namespace) > 0 && !r.Namespaced {
+				continue
+			}
+			if unownedResources.Has(gv.WithResource(r.Name).GroupResource().String()) {
+				continue
+			}
+			resources = append(resources, gv.WithResource(r.Name))
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		objects []*unstructured.Unstructured
+		seen    = map[types.UID]bool{}
+	)
+	sem := make(chan struct{}, o.Concurrency)
+	for _, gvr := range resources {
+		wg.Add(1)
+		go func(gvr schema.GroupVersionResource) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			options := metav1.ListOptions{Limit: o.ChunkSize}
+			for {
+				list, err := client.Resource(gvr).Namespace(namespace).List(context.TODO(), options)
+				if err != nil {
+					if !apierrors.IsForbidden(err) && !apierrors.IsNotFound(err) && !apierrors.IsMethodNotSupported(err) {
+						fmt.Fprintf(o.ErrOut, "warning: unable to list %s: %v\n", gvr.GroupResource(), err)
+					}
+					klog.V(2).Infof("Unable to list %s: %v", gvr, err)
+					return
+				}
+				mu.Lock()
+				// the same objects are served by several groups
+				for i := range list.Items {
+					if uid := list.Items[i].GetUID(); !seen[uid] {
+						seen[uid] = true
+						objects = append(objects, &list.Items[i])
+					}
+				}
+				mu.Unlock()
+				if options.Continue = list.GetContinue(); len(options.Continue) == 0 {
+					return
+				}
+			}
+		}(gvr)
+	}
+	wg.Wait()
+	return objects, nil
+}
+
+// treeBuilder builds the tree of an object from the objects that may be
+// owned by it.
+type treeBuilder struct {
+	objects []*unstructured.Unstructured
+	owned   map[types.UID][]*unstructured.Unstructured
+	depth   int
+	showAll bool
+	// visited guards against owner references forming a cycle
+	visited map[types.UID]bool
+}
+
+// build returns the node of obj at level with the objects it owns, down to
+// depth levels below the root unless depth is 0.
+func (b *treeBuilder) build(obj *unstructured.Unstructured, level int) *treeNode {
+	node := &treeNode{object: obj}
+	b.visited[obj.GetUID()] = true
+	if b.depth > 0 && level >= b.depth {
+		return node
+	}
+	children := append([]*unstructured.Unstructured{}, b.owned[obj.GetUID()]...)
+	children = append(children, claimsOf(obj, b.objects)...)
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].GetKind() != children[j].GetKind() {
+			return children[i].GetKind() < children[j].GetKind()
+		}
+		return children[i].GetName() < children[j].GetName()
+	})
+	for _, child := range children {
+		if b.visited[child.GetUID()] || !b.showAll && isCompleted(child) {
+			continue
+		}
+		node.children = append(node.children, b.build(child, level+1))
+	}
+	return node
+}
+
+// claimsOf returns the PersistentVolumeClaims created from the
+// volumeClaimTemplates of a StatefulSet, which it does not own.
+func claimsOf(obj *unstructured.Unstructured, objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	if obj.GroupVersionKind().GroupKind() != (schema.GroupKind{Group: "apps", Kind: "StatefulSet"}) {
+		return nil
+	}
+	templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "volumeClaimTemplates")
+	var claims []*unstructured.Unstructured
+	for _, t := range templates {
+		template, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(template, "metadata", "name")
+		// claims are named <template>-<statefulset>-<ordinal>
+		prefix := name + "-" + obj.GetName() + "-"
+		for _, candidate := range objects {
+			if candidate.GetKind() != "PersistentVolumeClaim" || candidate.GetNamespace() != obj.GetNamespace() ||
+				len(candidate.GetOwnerReferences()) > 0 || !strings.HasPrefix(candidate.GetName(), prefix) {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(candidate.GetName(), prefix)); err == nil {
+				claims = append(claims, candidate)
+			}
+		}
+	}
+	return claims
+}
+
+// isCompleted reports whether obj is done with its work and hidden without
+// --show-all: a Pod or Job that ended, or a ReplicaSet scaled down to zero.
+func isCompleted(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "Pod":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "Succeeded" || phase == "Failed"
+	case "Job":
+		status, _, _ := unstructured.NestedMap(obj.Object, "status")
+		conditions := statusConditions(status)
+		return conditions["Complete"].status == "True" || conditions["Failed"].status == "True"
+	case "ReplicaSet", "ReplicationController":
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		current, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+		return found && replicas == 0 && current == 0
+	}
+	return false
+}
+
+// treeColumns are the columns of the kind of every node printed next to it.
+var treeColumns = []string{"Ready", "Status", "Age"}
+
+// printTree prints tree as a table whose NAME column is indented by level.
+func (o *GetOptions) printTree(tree *treeNode, withNamespace bool) error {
+	table := &metav1.Table{}
+	if withNamespace {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: "Namespace", Type: "string"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"})
+	for _, name := range treeColumns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: name, Type: "string"})
+	}
+
+	converter := o.newTableConverter()
+	var walk func(node *treeNode, prefix, childPrefix string) error
+	walk = func(node *treeNode, prefix, childPrefix string) error {
+		cells, err := treeCells(converter, node.object)
+		if err != nil {
+			return err
+		}
+		row := metav1.TableRow{Object: runtime.RawExtension{Object: node.object}}
+		if withNamespace {
+			row.Cells = append(row.Cells, node.object.GetNamespace())
+		}
+		row.Cells = append(row.Cells, prefix+node.object.GetKind()+"/"+node.object.GetName())
+		row.Cells = append(row.Cells, cells...)
+		table.Rows = append(table.Rows, row)
+		for i, child := range node.children {
+			if i == len(node.children)-1 {
+				err = walk(child, childPrefix+"└─", childPrefix+"  ")
+			} else {
+				err = walk(child, childPrefix+"├─", childPrefix+"│ ")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree, "", ""); err != nil {
+		return err
+	}
+
+	o.colorTheme.colorTable(table)
+	w := o.newTableWriter(o.Out)
+	if err := printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// treeCells returns the treeColumns of obj, as printed for its kind. Kinds
+// without a STATUS column show the status computeStatus derives.
+func treeCells(converter *tableConverter, obj *unstructured.Unstructured) ([]interface{}, error) {
+	table, err := converter.ToTable(obj)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]interface{}, 0, len(treeColumns))
+	for _, name := range treeColumns {
+		i, err := columnIndex(table, name, "tree")
+		switch {
+		case err == nil && len(table.Rows) > 0:
+			cells = append(cells, cellAt(table.Rows[0].Cells, i))
+		case name == "Status":
+			status, _ := computeStatus(obj)
+			cells = append(cells, status)
+		case name == "Age":
+			cells = append(cells, translateTimestampSince(obj.GetCreationTimestamp()))
+		default:
+			cells = append(cells, "")
+		}
+	}
+	return cells, nil
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// addWhereFlag binds --where to o.Where.
+func addWhereFlag(cmd *cobra.Command, o *GetOptions) {
+	cmd.Flags().StringVar(&o.Where, "where", o.Where, "Only print the rows whose columns match this expression, e.g. 'STATUS!=Running && RESTARTS>5' or 'NAME=~^web- || AGE<10m'. Supports ==, !=, <, <=, >, >=, =~ and !~ (regular expressions), &&, ||, ! and parentheses. Integer columns compare as numbers and ages as durations.")
+}
+
+// whereExpr is a parsed --where expression, evaluated against the cells of a
+// Table row.
+type whereExpr interface {
+	eval(row *whereRow) (bool, error)
+	// columns returns the names of the columns compared
+	columns() []string
+}
+
+// whereRow gives expressions access to the cells of a row by column name.
+type whereRow struct {
+	table *metav1.Table
+	cells []interface{}
+}
+
+// column returns the definition and the cell of the named column, ignoring
+// case, or false if the kind of the row has no such column.
+func (r *whereRow) column(name string) (metav1.TableColumnDefinition, interface{}, bool) {
+	for i, column := range r.table.ColumnDefinitions {
+		if strings.EqualFold(column.Name, name) {
+			return column, cellAt(r.cells, i), true
+		}
+	}
+	return metav1.TableColumnDefinition{}, nil, false
+}
+
+// filterRows removes the rows of table that do not match expr. Comparing a
+// column the table does not have is false; printedColumns rejects the columns
+// that no printed kind has.
+func filterRows(expr whereExpr, table *metav1.Table) error {
+	rows := table.Rows[:0]
+	for _, row := range table.Rows {
+		ok, err := expr.eval(&whereRow{table: table, cells: row.Cells})
+		if err != nil {
+			return err
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+	table.Rows = rows
+	return nil
+}
+
+type whereAnd struct{ left, right whereExpr }
+
+func (e whereAnd) eval(row *whereRow) (bool, error) {
+	ok, err := e.left.eval(row)
+	if err != nil || !ok {
+		return false, err
+	}
+	return e.right.eval(row)
+}
+
+func (e whereAnd) columns() []string {
+	return append(e.left.columns(), e.right.columns()...)
+}
+
+type whereOr struct{ left, right whereExpr }
+
+func (e whereOr) eval(row *whereRow) (bool, error) {
+	ok, err := e.left.eval(row)
+	if err != nil || ok {
+		return ok, err
+	}
+	return e.right.eval(row)
+}
+
+func (e whereOr) columns() []string {
+	return append(e.left.columns(), e.right.columns()...)
+}
+
+type whereNot struct{ expr whereExpr }
+
+func (e whereNot) eval(row *whereRow) (bool, error) {
+	ok, err := e.expr.eval(row)
+	return !ok, err
+}
+
+func (e whereNot) columns() []string {
+	return e.expr.columns()
+}
+
+// whereComparison compares the cell of a column with a literal. How the two
+// are compared depends on the Type of the column: integer and number columns
+// compare numerically, date columns and ages like 5d3h compare as durations,
+// anything else as strings.
+type whereComparison struct {
+	column string
+	op     string
+	value  string
+	regexp *regexp.Regexp
+}
+
+func (c whereComparison) eval(row *whereRow) (bool, error) {
+	column, cell, ok := row.column(c.column)
+	if !ok {
+		return false, nil
+	}
+	s := ""
+	if cell != nil {
+		s = fmt.Sprint(cell)
+	}
+
+	switch c.op {
+	case "=~":
+		return c.regexp.MatchString(s), nil
+	case "!~":
+		return !c.regexp.MatchString(s), nil
+	}
+
+	switch column.Type {
+	case "integer", "number":
+		value, err := strconv.ParseFloat(c.value, 64)
+		if err != nil {
+			return false, fmt.Errorf("--where: column %s holds numbers, %q is not one", strings.ToUpper(column.Name), c.value)
+		}
+		n, ok := leadingNumber(s)
+		if !ok {
+			// <none>, <unknown> and the like never match
+			return false, nil
+		}
+		return compare(c.op, n-value), nil
+	case "date":
+		value, err := parseAge(c.value)
+		if err != nil {
+			return false, fmt.Errorf("--where: column %s holds ages, %v", strings.ToUpper(column.Name), err)
+		}
+		age, err := parseAge(s)
+		if err != nil {
+			return false, nil
+		}
+		return compare(c.op, float64(age-value)), nil
+	}
+
+	if c.op == "==" || c.op == "!=" {
+		return compare(c.op, float64(strings.Compare(s, c.value))), nil
+	}
+	// string columns, such as the AGE of built-in kinds, are ordered as ages
+	// or numbers when compared with one
+	if value, err := strconv.ParseFloat(c.value, 64); err == nil {
+		n, ok := leadingNumber(s)
+		return ok && compare(c.op, n-value), nil
+	}
+	if value, err := parseAge(c.value); err == nil {
+		age, err := parseAge(s)
+		return err == nil && compare(c.op, float64(age-value)), nil
+	}
+	return compare(c.op, float64(strings.Compare(s, c.value))), nil
+}
+
+func (c whereComparison) columns() []string {
+	return []string{c.column}
+}
+
+// compare applies op to the sign of the difference of two values.
+func compare(op string, diff float64) bool {
+	switch op {
+	case "==":
+		return diff == 0
+	case "!=":
+		return diff != 0
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case ">":
+		return diff > 0
+	case ">=":
+		return diff >= 0
+	}
+	return false
+}
+
+// leadingNumber parses the number s starts with, so that cells like
+// "5 (3m ago)" compare as 5.
+func leadingNumber(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(fields[0], 64)
+	return n, err == nil
+}
+
+var ageUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// parseAge parses the ages printed by duration.HumanDuration, like 35s, 5d3h
+// or 2y, as well as RFC3339 timestamps, which are turned into their age.
+func parseAge(s string) (time.Duration, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return time.Since(t), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if len(s) == 0 {
+		return 0, fmt.Errorf("empty age")
+	}
+	var age time.Duration
+	rest := s
+	for len(rest) > 0 {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, fmt.Errorf("%q is not an age like 90s, 5m or 2d3h", s)
+		}
+		unit, ok := ageUnits[rest[i]]
+		if !ok {
+			return 0, fmt.Errorf("%q is not an age like 90s, 5m or 2d3h", s)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, err
+		}
+		age += time.Duration(n) * unit
+		rest = rest[i+1:]
+	}
+	return age, nil
+}
+
+// whereOperators are matched longest first.
+var whereOperators = []string{"==", "!=", "<=", ">=", "=~", "!~", "=", "<", ">"}
+
+// whereParser is a recursive descent parser for the --where language:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = COLUMN op VALUE
+//	op         = "==" | "=" | "!=" | "<" | "<=" | ">" | ">=" | "=~" | "!~"
+//
+// VALUE is a word or a single or double quoted string.
+type whereParser struct {
+	input string
+	pos   int
+}
+
+// parseWhere parses a --where expression such as
+// 'STATUS!=Running && RESTARTS>5'.
+func parseWhere(input string) (whereExpr, error) {
+	p := &whereParser{input: input}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("--where %q: %v", input, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("--where %q: unexpected %q at offset %d", input, p.input[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+func (p *whereParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips token if the input continues with it.
+func (p *whereParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) parseOr() (whereExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = whereOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (whereExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = whereAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseUnary() (whereExpr, error) {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], "!") && !strings.HasPrefix(p.input[p.pos:], "!=") && !strings.HasPrefix(p.input[p.pos:], "!~") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return whereNot{expr: expr}, nil
+	}
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ')' at offset %d", p.pos)
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (whereExpr, error) {
+	p.skipSpaces()
+	column := p.parseColumn()
+	if len(column) == 0 {
+		return nil, fmt.Errorf("expected a column name at offset %d", p.pos)
+	}
+
+	p.skipSpaces()
+	c := whereComparison{column: column}
+	for _, op := range whereOperators {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			c.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if len(c.op) == 0 {
+		return nil, fmt.Errorf("expected one of %s after column %s", strings.Join(whereOperators, " "), column)
+	}
+	if c.op == "=" {
+		c.op = "=="
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	c.value = value
+	if c.op == "=~" || c.op == "!~" {
+		if c.regexp, err = regexp.Compile(value); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// parseColumn reads a column name. Names may contain balanced parentheses,
+// like PORT(S); names holding spaces, like "NOMINATED NODE", are quoted.
+func (p *whereParser) parseColumn() string {
+	if p.pos < len(p.input) && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+		value, err := p.parseValue()
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+	start, depth := p.pos, 0
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		switch {
+		case ch == '(' && p.pos > start:
+			depth++
+		case ch == ')' && depth > 0:
+			depth--
+		case unicode.IsSpace(rune(ch)) || strings.IndexByte("()=!<>~&|", ch) != -1:
+			return p.input[start:p.pos]
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// parseValue reads a quoted string or a word ending at a space, a closing
+// parenthesis or an && or || operator.
+func (p *whereParser) parseValue() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+		quote := p.input[p.pos]
+		var b strings.Builder
+		for i := p.pos + 1; i < len(p.input); i++ {
+			ch := p.input[i]
+			if ch == '\\' && i+1 < len(p.input) {
+				i++
+				b.WriteByte(p.input[i])
+				continue
+			}
+			if ch == quote {
+				p.pos = i + 1
+				return b.String(), nil
+			}
+			b.WriteByte(ch)
+		}
+		return "", fmt.Errorf("unterminated string at offset %d", p.pos)
+	}
+	start := p.pos
+	for p.pos < len(p.input) {
+		rest := p.input[p.pos:]
+		if unicode.IsSpace(rune(rest[0])) || rest[0] == ')' || strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||") {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected a value at offset %d", p.pos)
+	}
+	return p.input[start:p.pos], nil
+}
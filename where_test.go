@@ -0,0 +1,144 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		input   string
+		columns []string
+		err     string
+	}{
+		{input: "STATUS=Running", columns: []string{"STATUS"}},
+		{input: "STATUS!=Running && RESTARTS>5", columns: []string{"STATUS", "RESTARTS"}},
+		{input: "NAME=~^web- || AGE<10m", columns: []string{"NAME", "AGE"}},
+		{input: "!(STATUS==Running) && (READY=='1/1' || READY==\"2/2\")", columns: []string{"STATUS", "READY", "READY"}},
+		{input: "PORT(S)=80/TCP", columns: []string{"PORT(S)"}},
+		{input: "'NOMINATED NODE'!=<none>", columns: []string{"NOMINATED NODE"}},
+		{input: "STATUS", err: "expected one of"},
+		{input: "=Running", err: "expected a column name"},
+		{input: "STATUS==", err: "expected a value"},
+		{input: "(STATUS==Running", err: "missing ')'"},
+		{input: "STATUS=='Running", err: "unterminated string"},
+		{input: "STATUS==Running )", err: "unexpected"},
+		{input: "NAME=~(web", err: "missing closing )"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, err := parseWhere(test.input)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if columns := expr.columns(); !reflect.DeepEqual(columns, test.columns) {
+				t.Errorf("expected columns %v, got %v", test.columns, columns)
+			}
+		})
+	}
+}
+
+func TestFilterRows(t *testing.T) {
+	newTable := func() *metav1.Table {
+		return &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{
+				{Name: "Name", Type: "string"},
+				{Name: "Status", Type: "string"},
+				{Name: "Restarts", Type: "integer"},
+				{Name: "Age", Type: "string"},
+			},
+			Rows: []metav1.TableRow{
+				{Cells: []interface{}{"web-0", "Running", int64(0), "5m"}},
+				{Cells: []interface{}{"web-1", "CrashLoopBackOff", int64(12), "2d3h"}},
+				{Cells: []interface{}{"db-0", "Pending", "<none>", "30s"}},
+			},
+		}
+	}
+	tests := []struct {
+		where string
+		names []string
+		err   string
+	}{
+		{where: "STATUS=Running", names: []string{"web-0"}},
+		{where: "status!=running", names: []string{"web-0", "web-1", "db-0"}},
+		{where: "RESTARTS>5", names: []string{"web-1"}},
+		{where: "RESTARTS<=5", names: []string{"web-0"}},
+		{where: "AGE<10m", names: []string{"web-0", "db-0"}},
+		{where: "AGE>=1d", names: []string{"web-1"}},
+		{where: "NAME=~^web- && !(STATUS==Running)", names: []string{"web-1"}},
+		{where: "NAME!~^web- || RESTARTS>=12", names: []string{"web-1", "db-0"}},
+		{where: "NODE==a", names: []string{}},
+		{where: "RESTARTS>five", err: "holds numbers"},
+	}
+	for _, test := range tests {
+		t.Run(test.where, func(t *testing.T) {
+			expr, err := parseWhere(test.where)
+			if err != nil {
+				t.Fatal(err)
+			}
+			table := newTable()
+			err = filterRows(expr, table)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, row := range table.Rows {
+				names = append(names, row.Cells[0].(string))
+			}
+			if !reflect.DeepEqual(names, test.names) {
+				t.Errorf("expected rows %v, got %v", test.names, names)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age      string
+		expected time.Duration
+		err      bool
+	}{
+		{age: "35s", expected: 35 * time.Second},
+		{age: "5m", expected: 5 * time.Minute},
+		{age: "1h30m", expected: 90 * time.Minute},
+		{age: "5d3h", expected: 5*24*time.Hour + 3*time.Hour},
+		{age: "2y", expected: 2 * 365 * 24 * time.Hour},
+		{age: "", err: true},
+		{age: "5", err: true},
+		{age: "5w", err: true},
+		{age: "d", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.age, func(t *testing.T) {
+			age, err := parseAge(test.age)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", age)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if age != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, age)
+			}
+		})
+	}
+}